@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage BCCh credentials",
+}
+
+var authVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the credentials are accepted by the BCCh API",
+	Long: `
+    Perform a minimal authenticated request to the BCCh API with the credentials in use
+    (flags, environment variables or the saved profile) and report whether they are valid.
+
+    Example:
+        bcch auth verify --profile work
+	`,
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
+		err := cfg.loadCredentials()
+		if err != nil {
+			return fmt.Errorf("error loading credentials: %w", err)
+		}
+
+		err = verifyCredentials(cmd.Context(), "check the user and password, and save them again with 'setCredentials'")
+
+		// placeholder for spinner last symbol
+		fmt.Println("")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("credentials for profile %q are valid\n", cfg.bcchClient.AuthConfig.Profile)
+		return nil
+	}),
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authVerifyCmd)
+}
+
+// verifyCredentials checks the credentials of the client against BCCh,
+// adding what to do next to the errors of rejected credentials: invalidHint
+// for invalid ones, as the fix depends on where they come from.
+func verifyCredentials(ctx context.Context, invalidHint string) error {
+	err := cfg.bcchClient.VerifyCredentials(ctx)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, bcch.ErrAccountLocked):
+		return fmt.Errorf("%w\nlog in at https://si3.bcentral.cl/Siete/es/Siete/API?respuesta= to activate API access or unlock the account", err)
+	case errors.Is(err, bcch.ErrInvalidCredentials):
+		return fmt.Errorf("%w\n%s", err, invalidHint)
+	default:
+		return fmt.Errorf("could not verify credentials: %w", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+// verifyCases sets up srv to answer valid, invalid and locked credentials.
+var verifyCases = []struct {
+	name  string
+	setup func(srv *bcchtest.Server)
+	want  int
+}{
+	{"valid", func(srv *bcchtest.Server) {}, ExitOK},
+	{"invalid", func(srv *bcchtest.Server) { srv.SetCredentials("other-user", "other-password") }, ExitInvalidCredentials},
+	{"locked", func(srv *bcchtest.Server) {
+		srv.FailNextWithCode(1, bcchtest.CodeInvalidCredentials, "Usuario bloqueado")
+	}, ExitAccountLocked},
+}
+
+func TestAuthVerifyCmd(t *testing.T) {
+	for _, c := range verifyCases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := bcchtest.NewServer()
+			defer srv.Close()
+			c.setup(srv)
+
+			_, err := executeCommand(t, srv, "auth", "verify")
+			if got := ExitCode(err); got != c.want {
+				t.Errorf("expected exit code %v, got %v (%v)", c.want, got, err)
+			}
+			if c.want == ExitInvalidCredentials && !strings.Contains(err.Error(), "'setCredentials'") {
+				t.Errorf("expected a hint to save the credentials, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSetCredentialsVerify(t *testing.T) {
+	for _, c := range verifyCases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := bcchtest.NewServer()
+			defer srv.Close()
+			c.setup(srv)
+
+			path := filepath.Join(t.TempDir(), "credentials.json")
+			t.Setenv(bcch.EnvCredentialsFile, path)
+
+			_, err := executeCommand(t, srv, "setCredentials", "--verify")
+			if got := ExitCode(err); got != c.want {
+				t.Errorf("expected exit code %v, got %v (%v)", c.want, got, err)
+			}
+			// the hint must not send users back to the command they ran
+			if err != nil && strings.Contains(err.Error(), "'setCredentials'") {
+				t.Errorf("expected no hint to run setCredentials again, got %v", err)
+			}
+
+			_, statErr := os.Stat(path)
+			if saved := statErr == nil; saved != (c.want == ExitOK) {
+				t.Errorf("expected credentials saved only when valid, saved: %v", saved)
+			}
+			if statErr != nil && !errors.Is(statErr, os.ErrNotExist) {
+				t.Fatal(statErr)
+			}
+		})
+	}
+}
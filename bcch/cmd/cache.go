@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage cached BCCh responses",
+	Long: `Responses from the BCCh API are cached in the user cache directory and reused between runs.
+These commands list, purge and prewarm the cached responses.`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cached responses",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := cfg.cache.Entries()
+		if err != nil {
+			return fmt.Errorf("error listing cache entries: %w", err)
+		}
+		slices.SortFunc(entries, func(a, b bcchcache.Entry) int {
+			return strings.Compare(describeCacheKey(a.Key).series, describeCacheKey(b.Key).series)
+		})
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERIES\tFIRST DATE\tLAST DATE\tAGE\tSIZE")
+		for _, entry := range entries {
+			k := describeCacheKey(entry.Key)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				k.series,
+				orDash(k.firstDate),
+				orDash(k.lastDate),
+				entry.Age().Truncate(time.Second),
+				formatSize(entry.Size),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print cache hit/miss statistics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		resetFlag, _ := cmd.Flags().GetBool("reset")
+		if resetFlag {
+			if err := cfg.cache.ResetStats(); err != nil {
+				return fmt.Errorf("error resetting cache stats: %w", err)
+			}
+			fmt.Fprintln(out, "cache stats reset!")
+			return nil
+		}
+
+		stats, err := cfg.cache.Stats()
+		if err != nil {
+			return fmt.Errorf("error reading cache stats: %w", err)
+		}
+		entries, err := cfg.cache.Entries()
+		if err != nil {
+			return fmt.Errorf("error listing cache entries: %w", err)
+		}
+		var size int64
+		for _, entry := range entries {
+			size += entry.Size
+		}
+
+		fmt.Fprintf(out, "Directory: %s\n", orDash(cfg.cache.Dir()))
+		fmt.Fprintf(out, "Entries:   %d\n", len(entries))
+		fmt.Fprintf(out, "Size:      %s\n", formatSize(size))
+		fmt.Fprintf(out, "Hits:      %d\n", stats.Hits)
+		fmt.Fprintf(out, "Misses:    %d\n", stats.Misses)
+		fmt.Fprintf(out, "Hit ratio: %.1f%%\n", stats.HitRatio()*100)
+		return nil
+	},
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove cached responses",
+	Long: `
+    Remove cached responses by series ID, by series ID pattern or by age.
+    Filters can be combined, removing the entries that match all of them;
+    use --all instead to remove every entry.
+
+    Example:
+        bcch cache purge --series F073.TCO.PRE.Z.D
+        bcch cache purge --pattern 'F049.*' --older-than 72h
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seriesFlag, _ := cmd.Flags().GetString("series")
+		patternFlag, _ := cmd.Flags().GetString("pattern")
+		olderThanFlag, _ := cmd.Flags().GetDuration("older-than")
+		allFlag, _ := cmd.Flags().GetBool("all")
+
+		if !allFlag && seriesFlag == "" && patternFlag == "" && olderThanFlag == 0 {
+			return errors.New("nothing to purge: use --series, --pattern, --older-than or --all")
+		}
+		if patternFlag != "" {
+			if _, err := path.Match(patternFlag, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", patternFlag, err)
+			}
+		}
+
+		entries, err := cfg.cache.Entries()
+		if err != nil {
+			return fmt.Errorf("error listing cache entries: %w", err)
+		}
+
+		var errs []error
+		removed := 0
+		for _, entry := range entries {
+			series := describeCacheKey(entry.Key).series
+			if seriesFlag != "" && series != seriesFlag {
+				continue
+			}
+			if patternFlag != "" {
+				if ok, _ := path.Match(patternFlag, series); !ok {
+					continue
+				}
+			}
+			if olderThanFlag != 0 && entry.Age() < olderThanFlag {
+				continue
+			}
+			if err := cfg.cache.Remove(entry.Key); err != nil {
+				errs = append(errs, fmt.Errorf("error removing %s: %w", series, err))
+				continue
+			}
+			removed++
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "purged %d cache entries\n", removed)
+		return errors.Join(errs...)
+	},
+}
+
+var cachePrewarmCmd = &cobra.Command{
+	Use:   "prewarm",
+	Short: "Fetch a predefined set of series into the cache",
+	Long: `
+    Fetch every series of a predefined set so that later commands, like viz, are served from the cache.
+    To check which set of series are available, take a look at 'search --predefined-sets'
+
+    Example:
+        bcch cache prewarm --set EMPLOYMENT
+	`,
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
+		if cfg.bcchClient.Offline {
+			return errors.New("cannot prewarm the cache in offline mode")
+		}
+
+		err := cfg.loadCredentials()
+		if err != nil {
+			return fmt.Errorf("error loading credentials: %w", err)
+		}
+
+		setNameFlag, _ := cmd.Flags().GetString("set")
+		setName := strings.ToUpper(setNameFlag)
+		set, ok := AvailableSetsSeries[setName]
+		if !ok {
+			return fmt.Errorf("set %q not found, available sets: %v", setName, slices.Sorted(maps.Keys(AvailableSetsSeries)))
+		}
+
+		results, fetchErr := cfg.series.GetMultipleSeriesDataContext(cmd.Context(), set.SeriesNames, "", "", nil)
+
+		// placeholder for spinner last symbol
+		fmt.Println("")
+		cached := 0
+		for _, result := range results {
+			if result.Status != bcch.FetchOK {
+				fmt.Printf("error fetching %s: %v\n", result.SeriesID, result.Err)
+				continue
+			}
+			cached++
+		}
+		fmt.Printf("cached %d of %d series from set %s\n", cached, len(set.SeriesNames), setName)
+		if fetchErr != nil {
+			return fmt.Errorf("error prewarming set %s: %w", setName, fetchErr)
+		}
+		return nil
+	}),
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd, cacheStatsCmd, cachePurgeCmd, cachePrewarmCmd)
+
+	cacheStatsCmd.Flags().Bool("reset", false, "reset hit/miss counters")
+
+	cachePurgeCmd.Flags().StringP("series", "s", "", "remove entries of this series ID")
+	cachePurgeCmd.Flags().String("pattern", "", "remove entries whose series ID matches this glob pattern")
+	cachePurgeCmd.Flags().Duration("older-than", 0, "remove entries older than this duration (e.g. 72h)")
+	cachePurgeCmd.Flags().Bool("all", false, "remove every entry")
+	cachePurgeCmd.MarkFlagsMutuallyExclusive("all", "series")
+	cachePurgeCmd.MarkFlagsMutuallyExclusive("all", "pattern")
+	cachePurgeCmd.MarkFlagsMutuallyExclusive("all", "older-than")
+
+	cachePrewarmCmd.Flags().String("set", "EMPLOYMENT", "predefined set of series to cache")
+}
+
+type cacheKeyInfo struct {
+	series    string
+	firstDate string
+	lastDate  string
+}
+
+// describeCacheKey extracts the series and date range from a cache key,
+// which holds the URL of the cached request without the credentials. Older
+// versions keyed the responses by the query alone.
+func describeCacheKey(key string) cacheKeyInfo {
+	rawQuery := key
+	if _, q, ok := strings.Cut(key, "?"); ok {
+		rawQuery = q
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return cacheKeyInfo{series: key}
+	}
+	switch query.Get("function") {
+	case "GetSeries":
+		return cacheKeyInfo{
+			series:    query.Get("timeseries"),
+			firstDate: query.Get("firstdate"),
+			lastDate:  query.Get("lastdate"),
+		}
+	case "SearchSeries":
+		return cacheKeyInfo{series: "search:" + query.Get("frequency")}
+	default:
+		return cacheKeyInfo{series: key}
+	}
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+func TestDescribeCacheKey(t *testing.T) {
+	cases := []struct {
+		key  string
+		want cacheKeyInfo
+	}{
+		{
+			key:  "https://si3.bcentral.cl/SieteRestWS/SieteRestWS.ashx?firstdate=2024-01-02&function=GetSeries&lastdate=2024-01-03&timeseries=F073.TCO.PRE.Z.D",
+			want: cacheKeyInfo{series: "F073.TCO.PRE.Z.D", firstDate: "2024-01-02", lastDate: "2024-01-03"},
+		},
+		{
+			key:  "http://127.0.0.1:8080/SieteRestWS.ashx?frequency=DAILY&function=SearchSeries",
+			want: cacheKeyInfo{series: "search:DAILY"},
+		},
+		// keys of older versions hold the query alone
+		{
+			key:  "function=GetSeries&timeseries=F073.TCO.PRE.Z.D",
+			want: cacheKeyInfo{series: "F073.TCO.PRE.Z.D"},
+		},
+	}
+	for _, c := range cases {
+		if got := describeCacheKey(c.key); got != c.want {
+			t.Errorf("describeCacheKey(%q) = %+v, want %+v", c.key, got, c.want)
+		}
+	}
+}
+
+// canceledSeries answers every multiple-series fetch as interrupted.
+type canceledSeries struct {
+	bcch.SeriesService
+}
+
+func (canceledSeries) GetMultipleSeriesDataContext(ctx context.Context, seriesIDs []string, firstDate, lastDate string, opts *bcch.FetchOptions) ([]bcch.SeriesResult, error) {
+	results := make([]bcch.SeriesResult, len(seriesIDs))
+	for i, id := range seriesIDs {
+		results[i] = bcch.SeriesResult{Index: i, SeriesID: id, Status: bcch.FetchCanceled, Err: context.Canceled}
+	}
+	return results, context.Canceled
+}
+
+func TestCachePrewarmCmdInterrupted(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	defer func(orig func(*bcch.Client) bcch.SeriesService) { newSeriesService = orig }(newSeriesService)
+	newSeriesService = func(*bcch.Client) bcch.SeriesService { return canceledSeries{} }
+
+	_, err := executeCommand(t, srv, "cache", "prewarm", "--set", "EMPLOYMENT")
+	if got := ExitCode(err); got != ExitInterrupted {
+		t.Errorf("expected exit code %v, got %v (%v)", ExitInterrupted, got, err)
+	}
+}
+
+const testEndpoint = "https://si3.bcentral.cl/SieteRestWS/SieteRestWS.ashx?"
+
+// testCacheEntries are written by newTestCache, stored the given age ago.
+var testCacheEntries = []struct {
+	key string
+	age time.Duration
+}{
+	{testEndpoint + "firstdate=2024-01-02&function=GetSeries&lastdate=2024-01-03&timeseries=F073.TCO.PRE.Z.D", time.Hour},
+	{testEndpoint + "function=GetSeries&timeseries=F073.TCO.PRE.Z.D", 100 * time.Hour},
+	{testEndpoint + "function=GetSeries&timeseries=F049.DES.TAS.INE.10.M", 100 * time.Hour},
+	{testEndpoint + "frequency=DAILY&function=SearchSeries", time.Hour},
+}
+
+// newTestCache returns a cache directory holding testCacheEntries, written
+// the way bcchcache stores them so that their age can be set.
+func newTestCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, entry := range testCacheEntries {
+		data, err := json.Marshal(map[string]any{
+			"key":       entry.key,
+			"createdAt": time.Now().UTC().Add(-entry.age),
+			"value":     []byte(`{"Codigo":0}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256([]byte(entry.key))
+		if err := os.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// cachedSeries returns the sorted series of the entries left in dir.
+func cachedSeries(t *testing.T, dir string) []string {
+	t.Helper()
+	cache, err := bcchcache.NewFileCache(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := []string{}
+	for _, entry := range entries {
+		series = append(series, describeCacheKey(entry.Key).series)
+	}
+	slices.Sort(series)
+	return series
+}
+
+func TestCacheLsCmd(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	out, err := executeCommandInCache(t, srv, newTestCache(t), "cache", "ls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 entries, got:\n%s", out)
+	}
+	// sorted by series, search entries without a date range
+	for i, want := range [][]string{
+		{"SERIES", "FIRST DATE", "LAST DATE", "AGE", "SIZE"},
+		{"F049.DES.TAS.INE.10.M", "-", "-", "100h0m", "12 B"},
+		{"F073.TCO.PRE.Z.D"},
+		{"F073.TCO.PRE.Z.D"},
+		{"search:DAILY", "-", "-", "1h0m", "12 B"},
+	} {
+		for _, field := range want {
+			if !strings.Contains(lines[i], field) {
+				t.Errorf("expected line %v to contain %q, got %q", i, field, lines[i])
+			}
+		}
+	}
+	if !strings.Contains(out, "2024-01-02  2024-01-03") {
+		t.Errorf("expected the date range of the entry, got:\n%s", out)
+	}
+}
+
+func TestCacheStatsCmd(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	dir := newTestCache(t)
+	if err := os.WriteFile(filepath.Join(dir, "stats.json"), []byte(`{"hits":3,"misses":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := executeCommandInCache(t, srv, dir, "cache", "stats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Directory: " + dir, "Entries:   4", "Size:      48 B", "Hits:      3", "Misses:    1", "Hit ratio: 75.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if _, err := executeCommandInCache(t, srv, dir, "cache", "stats", "--reset"); err != nil {
+		t.Fatalf("unexpected error resetting stats: %v", err)
+	}
+	out, err = executeCommandInCache(t, srv, dir, "cache", "stats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Hits:      0") || !strings.Contains(out, "Entries:   4") {
+		t.Errorf("expected reset counters and entries kept, got:\n%s", out)
+	}
+}
+
+func TestCacheStatsFailedCommands(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	// misses of failing commands are counted too
+	_, err := executeCommandInCache(t, srv, dir, "get", "--series", "F073.TCO.PRE.Z.D", "--offline")
+	if !errors.Is(err, bcch.ErrNotCached) {
+		t.Fatalf("expected not cached error, got %v", err)
+	}
+	out, err := executeCommandInCache(t, srv, dir, "cache", "stats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Misses:    1") {
+		t.Errorf("expected the miss of the failed command, got:\n%s", out)
+	}
+}
+
+func TestCachePurgeCmd(t *testing.T) {
+	const (
+		daily   = "F073.TCO.PRE.Z.D"
+		monthly = "F049.DES.TAS.INE.10.M"
+		search  = "search:DAILY"
+	)
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		kept    []string
+	}{
+		{"series", []string{"--series", daily}, false, []string{monthly, search}},
+		{"pattern", []string{"--pattern", "F049.*"}, false, []string{daily, daily, search}},
+		{"older than", []string{"--older-than", "72h"}, false, []string{daily, search}},
+		{"series and older than", []string{"--series", daily, "--older-than", "72h"}, false, []string{monthly, daily, search}},
+		{"pattern and older than", []string{"--pattern", "F0*", "--older-than", "72h"}, false, []string{daily, search}},
+		{"no match", []string{"--series", "NOT.CACHED"}, false, []string{monthly, daily, daily, search}},
+		{"all", []string{"--all"}, false, []string{}},
+		{"all with a filter", []string{"--all", "--series", daily}, true, []string{monthly, daily, daily, search}},
+		{"no filter", nil, true, []string{monthly, daily, daily, search}},
+		{"invalid pattern", []string{"--pattern", "[F049"}, true, []string{monthly, daily, daily, search}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := bcchtest.NewServer()
+			defer srv.Close()
+			dir := newTestCache(t)
+
+			_, err := executeCommandInCache(t, srv, dir, append([]string{"cache", "purge"}, c.args...)...)
+			if (err != nil) != c.wantErr {
+				t.Errorf("expected error: %v, got %v", c.wantErr, err)
+			}
+			kept := slices.Sorted(slices.Values(c.kept))
+			if got := cachedSeries(t, dir); !slices.Equal(got, kept) {
+				t.Errorf("expected entries of %v to be kept, got %v", kept, got)
+			}
+		})
+	}
+}
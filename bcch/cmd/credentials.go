@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+)
+
+// legacyCredentials is the file in the working directory where older
+// versions saved the credentials, before they moved to the user config
+// directory.
+const legacyCredentials = ".bcch_credentials" // #nosec G101
+
+// migrateLegacyCredentials moves the credentials at legacyPath into the
+// default profile of the credentials file of auth, so that no plaintext copy
+// is left behind. legacyPath is only removed once its credentials are saved
+// there: when the default profile already holds other credentials it is left
+// in place with a warning, so that nothing is lost. A missing legacyPath is
+// not an error.
+func migrateLegacyCredentials(auth bcch.AuthConfig, legacyPath string, w io.Writer) error {
+	dat, err := os.ReadFile(legacyPath) // #nosec G304 -- fixed file name in the working directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", legacyPath, err)
+	}
+	var legacy bcch.AuthConfig
+	if err := json.Unmarshal(dat, &legacy); err != nil {
+		return fmt.Errorf("error reading %s: %w", legacyPath, err)
+	}
+	path, err := auth.FilePath()
+	if err != nil {
+		return err
+	}
+
+	var saved bcch.AuthConfig
+	ok := false
+	if _, err := os.Stat(path); err == nil {
+		if saved, ok, err = savedDefaultProfile(auth, path); err != nil {
+			fmt.Fprintf(w, "warning: left %s in place, could not compare it with %s: %v\n", legacyPath, path, err)
+			return nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	switch {
+	case !ok:
+		migrated := bcch.AuthConfig{
+			User:     legacy.User,
+			Password: legacy.Password,
+			Profile:  bcch.DefaultProfile,
+			Path:     path,
+			Store:    auth.Store,
+		}
+		if err := migrated.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "moved credentials from %s to %s\n", legacyPath, path)
+	case saved.User == legacy.User && saved.Password == legacy.Password:
+		fmt.Fprintf(w, "removed %s, its credentials are saved in %s\n", legacyPath, path)
+	default:
+		fmt.Fprintf(w, "warning: left %s in place, its credentials differ from the default profile in %s; "+
+			"save them with 'setCredentials --profile <name>' and remove the file\n", legacyPath, path)
+		return nil
+	}
+
+	if err := os.Remove(legacyPath); err != nil {
+		return fmt.Errorf("could not remove %s, which holds the credentials in plain text: %w", legacyPath, err)
+	}
+	return nil
+}
+
+// savedDefaultProfile returns the default profile saved in the credentials
+// file at path, read as is, without the overrides of the environment that
+// Load applies. It reports false when the file has no default profile.
+func savedDefaultProfile(auth bcch.AuthConfig, path string) (bcch.AuthConfig, bool, error) {
+	store := auth.Store
+	if store == nil {
+		store = bcch.DefaultCredentialStore()
+	}
+	dat, err := store.ReadFile(path)
+	if err != nil {
+		return bcch.AuthConfig{}, false, err
+	}
+	var saved struct {
+		Profiles map[string]bcch.AuthConfig `json:"profiles"`
+	}
+	if err := json.Unmarshal(dat, &saved); err != nil {
+		return bcch.AuthConfig{}, false, fmt.Errorf("error during unmarshal of credentials (JSON): %w", err)
+	}
+	profile, ok := saved.Profiles[bcch.DefaultProfile]
+	return profile, ok, nil
+}
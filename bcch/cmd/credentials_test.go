@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+// writeLegacyCredentials writes credentials the way older versions did,
+// in plain text, and returns the file.
+func writeLegacyCredentials(t *testing.T, dir string) string {
+	t.Helper()
+	legacy := filepath.Join(dir, legacyCredentials)
+	if err := os.WriteFile(legacy, []byte(`{"user":"old-user","password":"old-pass"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return legacy
+}
+
+func loadProfile(t *testing.T, path, profile string) bcch.AuthConfig {
+	t.Helper()
+	auth := bcch.AuthConfig{Profile: profile, Path: path}
+	if err := auth.Load(); err != nil {
+		t.Fatalf("unexpected error loading profile %q: %v", profile, err)
+	}
+	return auth
+}
+
+func TestMigrateLegacyCredentials(t *testing.T) {
+	legacy := writeLegacyCredentials(t, t.TempDir())
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	var out bytes.Buffer
+	if err := migrateLegacyCredentials(bcch.AuthConfig{Profile: "work", Path: path}, legacy, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def := loadProfile(t, path, bcch.DefaultProfile); def.User != "old-user" || def.Password != "old-pass" {
+		t.Errorf("expected legacy credentials in the default profile, got %+v", def)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected %s to be removed, got %v", legacy, err)
+	}
+	if out.Len() == 0 {
+		t.Errorf("expected the migration to be reported")
+	}
+
+	// a missing legacy file is nothing to migrate
+	if err := migrateLegacyCredentials(bcch.AuthConfig{Path: path}, legacy, &out); err != nil {
+		t.Errorf("unexpected error without a legacy file: %v", err)
+	}
+}
+
+func TestMigrateLegacyCredentialsExistingFile(t *testing.T) {
+	cases := []struct {
+		name        string
+		saved       []bcch.AuthConfig
+		wantRemoved bool
+		wantDefault string
+	}{
+		{
+			name:        "other credentials",
+			saved:       []bcch.AuthConfig{{User: "new-user", Password: "new-pass"}},
+			wantRemoved: false,
+			wantDefault: "new-user",
+		},
+		{
+			name:        "other password",
+			saved:       []bcch.AuthConfig{{User: "old-user", Password: "new-pass"}},
+			wantRemoved: false,
+			wantDefault: "old-user",
+		},
+		{
+			name:        "same credentials",
+			saved:       []bcch.AuthConfig{{User: "old-user", Password: "old-pass"}},
+			wantRemoved: true,
+			wantDefault: "old-user",
+		},
+		{
+			name:        "no default profile",
+			saved:       []bcch.AuthConfig{{User: "work-user", Password: "work-pass", Profile: "work"}},
+			wantRemoved: true,
+			wantDefault: "old-user",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			legacy := writeLegacyCredentials(t, t.TempDir())
+			path := filepath.Join(t.TempDir(), "credentials.json")
+			for _, saved := range c.saved {
+				saved.Path = path
+				if err := saved.Save(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var out bytes.Buffer
+			if err := migrateLegacyCredentials(bcch.AuthConfig{Path: path}, legacy, &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if def := loadProfile(t, path, bcch.DefaultProfile); def.User != c.wantDefault {
+				t.Errorf("expected default profile of %s, got %+v", c.wantDefault, def)
+			}
+			_, err := os.Stat(legacy)
+			if removed := errors.Is(err, os.ErrNotExist); removed != c.wantRemoved {
+				t.Errorf("expected %s removed: %v, got %v (%v)", legacy, c.wantRemoved, removed, err)
+			}
+			if !c.wantRemoved && !strings.Contains(out.String(), "warning") {
+				t.Errorf("expected a warning for the file left in place, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyCredentialsStatError(t *testing.T) {
+	dir := t.TempDir()
+	legacy := writeLegacyCredentials(t, dir)
+	// the parent of the credentials file is a file, so it cannot be checked
+	path := filepath.Join(legacy, "credentials.json")
+
+	if err := migrateLegacyCredentials(bcch.AuthConfig{Path: path}, legacy, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected error checking the credentials file")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("expected %s to be kept, got %v", legacy, err)
+	}
+}
+
+func TestSetCredentialsMigratesLegacyFile(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	legacy := writeLegacyCredentials(t, dir)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	t.Setenv(bcch.EnvCredentialsFile, path)
+
+	if _, err := executeCommand(t, srv, "setCredentials", "--profile", "work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected %s to be removed, got %v", legacy, err)
+	}
+	if def := loadProfile(t, path, bcch.DefaultProfile); def.User != "old-user" {
+		t.Errorf("expected legacy credentials in the default profile, got %+v", def)
+	}
+	if work := loadProfile(t, path, "work"); work.User != bcchtest.User {
+		t.Errorf("expected new credentials in profile work, got %+v", work)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+)
+
+// Exit codes returned by bcch, so scripts can tell failures apart.
+const (
+	ExitOK                 = 0
+	ExitError              = 1
+	ExitInvalidCredentials = 3
+	ExitUnknownSeries      = 4
+	ExitInvalidDateRange   = 5
+	ExitRateLimited        = 6
+	ExitUpstream           = 7
+	ExitNotCached          = 8
+	ExitAccountLocked      = 9
+	ExitInterrupted        = 130
+)
+
+// ExitCode maps the error returned by Execute to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, bcch.ErrAccountLocked):
+		return ExitAccountLocked
+	case errors.Is(err, bcch.ErrInvalidCredentials):
+		return ExitInvalidCredentials
+	case errors.Is(err, bcch.ErrUnknownSeries):
+		return ExitUnknownSeries
+	case errors.Is(err, bcch.ErrInvalidDateRange):
+		return ExitInvalidDateRange
+	case errors.Is(err, bcch.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, bcch.ErrUpstream):
+		return ExitUpstream
+	case errors.Is(err, bcch.ErrNotCached):
+		return ExitNotCached
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	default:
+		return ExitError
+	}
+}
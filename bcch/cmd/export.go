@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/fileio"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/spf13/cobra"
+)
+
+// File formats written by export, and by get with --output.
+const (
+	formatParquet = "parquet"
+	formatXLSX    = "xlsx"
+	formatSQLite  = "sqlite"
+)
+
+var exportFormats = []string{formatParquet, formatXLSX, formatSQLite}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a predefined set of series to a file",
+	Long: `
+    Fetch every series of a predefined set and save them to a file for data platforms.
+    To check which set of series are available, take a look at 'search --predefined-sets'
+
+    Parquet files are written in long layout by default, with one row per observation and the
+    series_id, date, value and status columns, or in wide layout with --layout wide, with a date
+    column and one column per series. Dates use the DATE logical type and values are doubles,
+    null where BCCh has no value.
+
+    Excel workbooks have a Combined sheet with the series aligned on their dates, one sheet per
+    series with its date, value and status, and a Metadata sheet with the catalog titles, frequency,
+    first and last observation of every series and when they were fetched. --layout does not apply.
+
+    SQLite databases are synced rather than overwritten: the series table, with the catalog metadata
+    of every series, and the observations table, keyed by series_id and date, are upserted on each
+    run, so revised values are updated and observations outside the fetched range are kept.
+
+    Example:
+        bcch export --set EMPLOYMENT --format parquet --out-file employment.parquet
+        bcch export --set EMPLOYMENT --layout wide --firstdate 2020-01-01
+        bcch export --set EMPLOYMENT --format xlsx
+        bcch export --set EMPLOYMENT --format sqlite --out-file bcch.sqlite --firstdate 2024-01-01
+	`,
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
+		setNameFlag, _ := cmd.Flags().GetString("set")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		layoutFlag, _ := cmd.Flags().GetString("layout")
+		outFileFlag, _ := cmd.Flags().GetString("out-file")
+		firstDateFlag, _ := cmd.Flags().GetString("firstdate")
+		lastDateFlag, _ := cmd.Flags().GetString("lastdate")
+
+		setName := strings.ToUpper(setNameFlag)
+		set, ok := AvailableSetsSeries[setName]
+		if !ok {
+			return fmt.Errorf("set %q not found, available sets: %v", setName, slices.Sorted(maps.Keys(AvailableSetsSeries)))
+		}
+		format := strings.ToLower(formatFlag)
+		if !slices.Contains(exportFormats, format) {
+			return fmt.Errorf("invalid export format %q, must be one of: %s", formatFlag, strings.Join(exportFormats, ", "))
+		}
+		layout, err := fileio.ParseLayout(strings.ToLower(layoutFlag))
+		if err != nil {
+			return err
+		}
+		if err := validateDates(firstDateFlag, lastDateFlag); err != nil {
+			return err
+		}
+		if outFileFlag == "" {
+			outFileFlag = strings.ToLower(setName) + "." + format
+		}
+
+		if err := cfg.loadCredentials(); err != nil {
+			return fmt.Errorf("error loading credentials: %w", err)
+		}
+
+		fetchedAt := time.Now()
+		series, fetchErr := fetchSeriesList(cmd, set.SeriesNames, firstDateFlag, lastDateFlag)
+		// placeholder for spinner last symbol
+		fmt.Fprintln(cmd.OutOrStdout())
+		if len(series) == 0 && fetchErr != nil {
+			return fetchErr
+		}
+		if err := exportSeries(cmd, format, layout, series, fetchedAt, outFileFlag); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "exported %d of %d series from set %s to %s\n", len(series), len(set.SeriesNames), setName, outFileFlag)
+		return fetchErr
+	}),
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("set", "EMPLOYMENT", "predefined set of series to export")
+	exportCmd.Flags().String("format", formatParquet, "file format: "+strings.Join(exportFormats, ", "))
+	exportCmd.Flags().String("layout", string(fileio.LongLayout), "table layout: long or wide")
+	exportCmd.Flags().String("out-file", "", "file to write (default: <set>.<format>)")
+	exportCmd.Flags().String("firstdate", "", "first date in YYYY-MM-DD format (optional)")
+	exportCmd.Flags().String("lastdate", "", "last date in YYYY-MM-DD format (optional)")
+}
+
+// exportSeries saves series, fetched at fetchedAt, to filename in one of
+// exportFormats.
+func exportSeries(cmd *cobra.Command, format string, layout fileio.Layout, series []bcch.Series, fetchedAt time.Time, filename string) error {
+	var err error
+	switch format {
+	case formatParquet:
+		err = fileio.SaveSeriesToParquet(series, layout, filename)
+	case formatXLSX:
+		err = fileio.SaveSeriesToXLSX(series, fetchCatalog(cmd, series), fetchedAt, filename)
+	case formatSQLite:
+		err = fileio.SaveSeriesToSQLite(series, fetchCatalog(cmd, series), fetchedAt, filename)
+	default:
+		err = fmt.Errorf("invalid export format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("error exporting series: %w", err)
+	}
+	return nil
+}
+
+// fetchCatalog fetches the catalog entries of the frequencies of series.
+// Catalogs that cannot be fetched are reported on the standard error and
+// skipped, leaving their series described by the series data alone.
+func fetchCatalog(cmd *cobra.Command, series []bcch.Series) []bcch.SeriesInfo {
+	var frequencies []bcch.Frequency
+	for _, s := range series {
+		if s.Frequency != bcch.FrequencyUnknown && !slices.Contains(frequencies, s.Frequency) {
+			frequencies = append(frequencies, s.Frequency)
+		}
+	}
+
+	var catalog []bcch.SeriesInfo
+	for _, frequency := range frequencies {
+		resp, err := cfg.series.GetAvailableSeriesContext(cmd.Context(), string(frequency))
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "error fetching catalog of %s series: %v\n", strings.ToLower(string(frequency)), err)
+			continue
+		}
+		catalog = append(catalog, resp.SeriesInfos...)
+	}
+	return catalog
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+)
+
+type parquetObservation struct {
+	SeriesID string   `parquet:"series_id"`
+	Date     int32    `parquet:"date,date"`
+	Value    *float64 `parquet:"value,optional"`
+	Status   string   `parquet:"status"`
+}
+
+func TestExportCmdParquet(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	outFile := filepath.Join(t.TempDir(), "employment.parquet")
+
+	// only some series of the set are served by the fake server
+	out, err := executeCommand(t, srv, "export", "--set", "employment", "--out-file", outFile)
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error for the missing series, got %v", err)
+	}
+	if !strings.Contains(out, "exported 3 of") {
+		t.Errorf("expected export summary, got:\n%s", out)
+	}
+
+	rows, err := parquet.ReadFile[parquetObservation](outFile)
+	if err != nil {
+		t.Fatalf("error reading parquet file: %v", err)
+	}
+	var ids []string
+	for _, row := range rows {
+		if !slices.Contains(ids, row.SeriesID) {
+			ids = append(ids, row.SeriesID)
+		}
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected rows of 3 series, got %v", ids)
+	}
+}
+
+func TestGetCmdParquet(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	outFile := filepath.Join(t.TempDir(), "series.parquet")
+	args := []string{"get", "--series", "F073.TCO.PRE.Z.D,F049.DES.TAS.INE.10.M", "--firstdate", "2024-01-01", "--lastdate", "2024-01-05", "--output", "parquet"}
+
+	if _, err := executeCommand(t, srv, args...); err == nil {
+		t.Errorf("expected error without --out-file")
+	}
+	if _, err := executeCommand(t, srv, append(args, "--out-file", outFile)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dat, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(dat), int64(len(dat)))
+	if err != nil {
+		t.Fatalf("error reading parquet file: %v", err)
+	}
+	// wide layout: one row per date from 2024-01-01 to 2024-01-05
+	if n := f.NumRows(); n != 5 {
+		t.Errorf("expected 5 rows, got %v", n)
+	}
+	// date first, then the series in the requested order
+	want := []string{"date", "F073.TCO.PRE.Z.D", "F049.DES.TAS.INE.10.M"}
+	var got []string
+	for _, path := range f.Schema().Columns() {
+		got = append(got, path[0])
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected columns %v, got %v", want, got)
+	}
+}
+
+func TestExportCmdXLSX(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	outFile := filepath.Join(t.TempDir(), "employment.xlsx")
+
+	out, err := executeCommand(t, srv, "export", "--set", "employment", "--format", "XLSX", "--out-file", outFile)
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error for the missing series, got %v", err)
+	}
+	if !strings.Contains(out, "exported 3 of") {
+		t.Errorf("expected export summary, got:\n%s", out)
+	}
+
+	f, err := excelize.OpenFile(outFile)
+	if err != nil {
+		t.Fatalf("error opening workbook: %v", err)
+	}
+	defer f.Close()
+	// combined, one sheet per exported series and metadata
+	if sheets := f.GetSheetList(); len(sheets) != 5 || sheets[0] != "Combined" || sheets[4] != "Metadata" {
+		t.Errorf("unexpected sheets %v", sheets)
+	}
+	metadata, err := f.GetRows("Metadata")
+	if err != nil {
+		t.Fatalf("error reading metadata sheet: %v", err)
+	}
+	if len(metadata) != 4 {
+		t.Errorf("expected a metadata row per exported series, got %v", metadata)
+	}
+	// titles and observation range come from the catalog
+	for _, row := range metadata {
+		if row[0] != "F049.DES.TAS.INE.10.M" {
+			continue
+		}
+		if row[3] != "Unemployment rate, total" || row[5] != "2023-01-01" || row[6] != "2024-03-01" {
+			t.Errorf("expected catalog metadata for F049.DES.TAS.INE.10.M, got %q", row)
+		}
+	}
+}
+
+func TestExportCmdSQLite(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	outFile := filepath.Join(t.TempDir(), "bcch.sqlite")
+	args := []string{"export", "--set", "employment", "--format", "sqlite", "--out-file", outFile}
+
+	// running twice upserts the same rows
+	for range 2 {
+		if _, err := executeCommand(t, srv, args...); !errors.Is(err, bcch.ErrUnknownSeries) {
+			t.Fatalf("expected unknown series error for the missing series, got %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var seriesCount, seriesWithObservations int
+	if err := db.QueryRow(`SELECT count(*) FROM series`).Scan(&seriesCount); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT count(DISTINCT series_id) FROM observations`).Scan(&seriesWithObservations); err != nil {
+		t.Fatal(err)
+	}
+	if seriesCount != 3 || seriesWithObservations != 3 {
+		t.Errorf("expected 3 series with observations, got %v series and %v with observations", seriesCount, seriesWithObservations)
+	}
+
+	var duplicates int
+	if err := db.QueryRow(`SELECT count(*) - count(DISTINCT series_id || date) FROM observations`).Scan(&duplicates); err != nil {
+		t.Fatal(err)
+	}
+	if duplicates != 0 {
+		t.Errorf("expected no duplicated observations, got %v", duplicates)
+	}
+
+	// metadata comes from the catalog
+	var title, createdAt string
+	err = db.QueryRow(`SELECT english_title, created_at FROM series WHERE series_id = ?`, "F049.DES.TAS.INE.10.M").Scan(&title, &createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Unemployment rate, total" || createdAt != "2010-01-01" {
+		t.Errorf("expected catalog metadata, got %q created at %v", title, createdAt)
+	}
+}
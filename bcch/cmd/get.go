@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/fileio"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
 	"github.com/spf13/cobra"
 )
 
@@ -11,56 +19,220 @@ const dateLayout = "2006-01-02"
 
 var getCmd = &cobra.Command{
 	Use:   "get",
-	Short: "Retrieve data from specific series ID",
+	Short: "Retrieve data from one or more series IDs",
 	Long: `
     Retrieve time series data from BCCh.
 
+    Several series can be requested at once by repeating --series, separating the IDs with commas
+    or listing them in a file, one per line. They are printed as a table with one column per series,
+    aligned on the observation dates: '-' marks dates without an observation of the series, as with
+    series of different frequencies or ranges, and 'NaN' observations without value.
+    Use --output to print CSV, TSV, JSON, NDJSON or Markdown instead, and --out-file to save it.
+    --output parquet saves the series to the Parquet file set with --out-file, in the layout set with --layout,
+    --output xlsx to an Excel workbook and --output sqlite syncs them into a SQLite database, as described
+    in 'export --help'.
+
     Example:
         bcch get --series UF --firstdate 2020-01-01 --lastdate 2021-01-01
+        bcch get --series F073.TCO.PRE.Z.D,F049.DES.TAS.INE.10.M --firstdate 2024-01-01
+        bcch get --series-file series.txt --output csv --out-file series.csv
 	`,
-	Run: withSpinnerWrapper(cfg.spinner, func(cmd *cobra.Command, args []string) {
-		err := cfg.bcchapiClient.AuthConfig.Load()
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
+		err := cfg.loadCredentials()
 		if err != nil {
-			fmt.Printf("error loading credentials: %v\n", err)
-			return
+			return fmt.Errorf("error loading credentials: %w", err)
 		}
-		creds := cfg.bcchapiClient.AuthConfig
-		if creds.User == "" || creds.Password == "" {
-			fmt.Println("you need to first set your BCCH credentials to use this command, see 'help' for details")
+		creds := cfg.bcchClient.AuthConfig
+		if !cfg.bcchClient.Offline && (creds.User == "" || creds.Password == "") {
+			fmt.Fprintln(cmd.OutOrStdout(), "you need to first set your BCCH credentials to use this command, see 'help' for details")
 		}
 
-		seriesFlag, _ := cmd.Flags().GetString("series")
+		seriesFlag, _ := cmd.Flags().GetStringSlice("series")
+		seriesFileFlag, _ := cmd.Flags().GetString("series-file")
 		firstDateFlag, _ := cmd.Flags().GetString("firstdate")
 		lastDateFlag, _ := cmd.Flags().GetString("lastdate")
 
-		if firstDateFlag != "" {
-			if _, err := time.Parse(dateLayout, firstDateFlag); err != nil {
-				fmt.Printf("Invalid firstdate '%s': must be YYYY-MM-DD\n", firstDateFlag)
-				return
-			}
+		if err := validateDates(firstDateFlag, lastDateFlag); err != nil {
+			return err
 		}
-		if lastDateFlag != "" {
-			if _, err := time.Parse(dateLayout, lastDateFlag); err != nil {
-				fmt.Printf("Invalid lastdate '%s': must be YYYY-MM-DD\n", lastDateFlag)
-				return
+
+		seriesIDs := seriesFlag
+		if seriesFileFlag != "" {
+			fileIDs, err := readSeriesFile(seriesFileFlag)
+			if err != nil {
+				return err
 			}
+			seriesIDs = append(seriesIDs, fileIDs...)
+		}
+		seriesIDs = uniqueSeriesIDs(seriesIDs)
+		if len(seriesIDs) == 0 {
+			return errors.New("no series given, use --series or --series-file")
 		}
 
-		seriesData, _ := cfg.bcchapiClient.GetSeriesData(seriesFlag, firstDateFlag, lastDateFlag)
-
-		fmt.Println(seriesData.Series.DescripEsp)
+		outputFlag, _ := cmd.Flags().GetString("output")
+		if exportFormat := strings.ToLower(outputFlag); slices.Contains(exportFormats, exportFormat) {
+			return getToFile(cmd, exportFormat, seriesIDs, firstDateFlag, lastDateFlag)
+		}
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
 
-		// placeholder for spinner last symbol
-		fmt.Println("")
-		for _, series := range seriesData.Series.Obs {
-			fmt.Printf("%v - %v\n", series.IndexDateString, series.Value)
+		series, fetchErr := fetchSeriesList(cmd, seriesIDs, firstDateFlag, lastDateFlag)
+		if humanOutput(cmd, format) {
+			// placeholder for spinner last symbol
+			fmt.Fprintln(cmd.OutOrStdout())
+			for _, s := range series {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", s.ID, s.SpanishTitle)
+			}
+			fmt.Fprintln(cmd.OutOrStdout())
 		}
+		if len(series) > 0 || fetchErr == nil {
+			if err := writeOutput(cmd, format, seriesData(series)); err != nil {
+				return err
+			}
+		}
+		return fetchErr
 	}),
 }
 
 func init() {
 	rootCmd.AddCommand(getCmd)
-	getCmd.Flags().StringP("series", "s", "", "series ID")
+	getCmd.Flags().StringSliceP("series", "s", nil, "series ID, repeat the flag or separate IDs with commas for several series")
+	getCmd.Flags().String("series-file", "", "file with one series ID per line, '#' starts a comment")
 	getCmd.Flags().String("firstdate", "", "first date in YYYY-MM-DD format (optional)")
 	getCmd.Flags().String("lastdate", "", "last date in YYYY-MM-DD format (optional)")
+	addOutputFlags(getCmd, exportFormats...)
+	getCmd.Flags().String("layout", string(fileio.WideLayout), "table layout of parquet output: long or wide")
+}
+
+// getToFile fetches the series and saves them to the file set with
+// --out-file in one of exportFormats.
+func getToFile(cmd *cobra.Command, format string, seriesIDs []string, firstDate, lastDate string) error {
+	outFileFlag, _ := cmd.Flags().GetString("out-file")
+	layoutFlag, _ := cmd.Flags().GetString("layout")
+	if outFileFlag == "" {
+		return fmt.Errorf("--output %s requires --out-file", format)
+	}
+	layout, err := fileio.ParseLayout(strings.ToLower(layoutFlag))
+	if err != nil {
+		return err
+	}
+
+	fetchedAt := time.Now()
+	series, fetchErr := fetchSeriesList(cmd, seriesIDs, firstDate, lastDate)
+	if len(series) == 0 && fetchErr != nil {
+		return fetchErr
+	}
+	if err := exportSeries(cmd, format, layout, series, fetchedAt, outFileFlag); err != nil {
+		return err
+	}
+	return fetchErr
+}
+
+// validateDates checks the optional --firstdate and --lastdate flags.
+// Errors match bcch.ErrInvalidDateRange.
+func validateDates(firstDate, lastDate string) error {
+	first, err := parseDateFlag("firstdate", firstDate)
+	if err != nil {
+		return err
+	}
+	last, err := parseDateFlag("lastdate", lastDate)
+	if err != nil {
+		return err
+	}
+	if !first.IsZero() && !last.IsZero() && first.After(last) {
+		return fmt.Errorf("%w: firstdate %s is after lastdate %s", bcch.ErrInvalidDateRange, firstDate, lastDate)
+	}
+	return nil
+}
+
+// parseDateFlag parses the value of a date flag, returning the zero time
+// for an empty value.
+func parseDateFlag(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid %s %q, must be YYYY-MM-DD", bcch.ErrInvalidDateRange, flag, value)
+	}
+	return t, nil
+}
+
+// fetchSeriesList fetches every series, reporting the ones that fail on
+// the standard error. It returns the fetched series along with the errors
+// of the failed ones.
+func fetchSeriesList(cmd *cobra.Command, seriesIDs []string, firstDate, lastDate string) ([]bcch.Series, error) {
+	if len(seriesIDs) == 1 {
+		resp, err := cfg.series.GetSeriesDataContext(cmd.Context(), seriesIDs[0], firstDate, lastDate)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching series %s: %w", seriesIDs[0], err)
+		}
+		s, err := resp.ToSeries()
+		if err != nil {
+			return nil, err
+		}
+		return []bcch.Series{s}, nil
+	}
+
+	results, fetchErr := cfg.series.GetMultipleSeriesDataContext(cmd.Context(), seriesIDs, firstDate, lastDate, nil)
+	var errs []error
+	if fetchErr != nil {
+		errs = append(errs, fetchErr)
+	}
+	series := make([]bcch.Series, 0, len(results))
+	for _, result := range results {
+		if result.Status != bcch.FetchOK {
+			fmt.Fprintf(cmd.ErrOrStderr(), "error fetching %s: %v\n", result.SeriesID, result.Err)
+			continue
+		}
+		s, err := result.Data.ToSeries()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "error reading %s: %v\n", result.SeriesID, err)
+			errs = append(errs, err)
+			continue
+		}
+		series = append(series, s)
+	}
+	return series, errors.Join(errs...)
+}
+
+// readSeriesFile reads one series ID per line from the file at path,
+// skipping blank lines and '#' comments.
+func readSeriesFile(path string) ([]string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading series file: %w", err)
+	}
+	defer f.Close()
+
+	var seriesIDs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			seriesIDs = append(seriesIDs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading series file: %w", err)
+	}
+	return seriesIDs, nil
+}
+
+// uniqueSeriesIDs trims the IDs and drops empty and repeated ones, keeping
+// the first occurrence.
+func uniqueSeriesIDs(seriesIDs []string) []string {
+	seen := make(map[string]bool, len(seriesIDs))
+	unique := make([]string, 0, len(seriesIDs))
+	for _, id := range seriesIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
 }
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+func TestGetCmd(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	out, err := executeCommand(t, srv, "get", "--series", "F073.TCO.PRE.Z.D", "--firstdate", "2024-01-02", "--lastdate", "2024-01-03")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Tipo de cambio", "2024-01-02  877.12", "2024-01-03  882.69"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "2024-01-04") {
+		t.Errorf("expected output to stop at lastdate, got:\n%s", out)
+	}
+}
+
+func TestGetCmdErrors(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	_, err := executeCommand(t, srv, "get", "--series", "NOT.A.SERIES")
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Fatalf("expected unknown series error, got %v", err)
+	}
+	if code := ExitCode(err); code != ExitUnknownSeries {
+		t.Errorf("expected exit code %v, got %v", ExitUnknownSeries, code)
+	}
+
+	for _, dates := range [][]string{
+		{"--firstdate", "2024-13-01"},
+		{"--lastdate", "01-01-2024"},
+		{"--firstdate", "2024-02-01", "--lastdate", "2024-01-01"},
+	} {
+		for _, command := range [][]string{{"get", "--series", "F073.TCO.PRE.Z.D"}, {"export"}} {
+			_, err = executeCommand(t, srv, append(command, dates...)...)
+			if code := ExitCode(err); code != ExitInvalidDateRange {
+				t.Errorf("%v %v: expected exit code %v, got %v (%v)", command[0], dates, ExitInvalidDateRange, code, err)
+			}
+		}
+	}
+
+	srv.SetCredentials("someone", "else")
+	_, err = executeCommand(t, srv, "get", "--series", "F073.TCO.PRE.Z.D")
+	if code := ExitCode(err); code != ExitInvalidCredentials {
+		t.Errorf("expected exit code %v, got %v (%v)", ExitInvalidCredentials, code, err)
+	}
+}
+
+func TestGetCmdMultipleSeries(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	seriesFile := filepath.Join(t.TempDir(), "series.txt")
+	if err := os.WriteFile(seriesFile, []byte("# unemployment\nF049.DES.TAS.INE.10.M\n\nF073.TCO.PRE.Z.D\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, args := range map[string][]string{
+		"repeated flag": {"--series", "F073.TCO.PRE.Z.D", "--series", "F049.DES.TAS.INE.10.M"},
+		"comma list":    {"--series", "F073.TCO.PRE.Z.D,F049.DES.TAS.INE.10.M"},
+		"series file":   {"--series", "F073.TCO.PRE.Z.D", "--series-file", seriesFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			args = append([]string{"get", "--firstdate", "2024-01-01", "--lastdate", "2024-01-05"}, args...)
+			out, err := executeCommand(t, srv, args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range []string{
+				"date        F073.TCO.PRE.Z.D  F049.DES.TAS.INE.10.M",
+				"2024-01-01  -                 8.4",
+				"2024-01-02  877.12            -",
+				"2024-01-05  NaN               -",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCmdMultipleSeriesErrors(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	out, err := executeCommand(t, srv, "get", "--series", "F073.TCO.PRE.Z.D,NOT.A.SERIES")
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error, got %v", err)
+	}
+	if !strings.Contains(out, "error fetching NOT.A.SERIES") || !strings.Contains(out, "877.12") {
+		t.Errorf("expected the error and the fetched series, got:\n%s", out)
+	}
+}
+
+func TestGetCmdOutput(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	args := []string{"get", "--series", "F073.TCO.PRE.Z.D,F049.DES.TAS.INE.10.M", "--firstdate", "2024-01-01", "--lastdate", "2024-01-05"}
+
+	out, err := executeCommand(t, srv, append(args, "--output", "csv")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCSV := `date,F073.TCO.PRE.Z.D,F049.DES.TAS.INE.10.M
+2024-01-01,,8.4
+2024-01-02,877.12,
+2024-01-03,882.69,
+2024-01-04,884.84,
+2024-01-05,NaN,
+`
+	if out != wantCSV {
+		t.Errorf("expected CSV output:\n%s\ngot:\n%s", wantCSV, out)
+	}
+
+	outFile := filepath.Join(t.TempDir(), "out", "series.ndjson")
+	if _, err := executeCommand(t, srv, append(args, "--output", "ndjson", "--out-file", outFile)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dat, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(dat)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %v:\n%s", len(lines), dat)
+	}
+	if want := `{"date":"2024-01-05","F073.TCO.PRE.Z.D":null,"F049.DES.TAS.INE.10.M":null}`; lines[4] != want {
+		t.Errorf("expected %s, got %s", want, lines[4])
+	}
+
+	if _, err := executeCommand(t, srv, append(args, "--output", "xml")...); err == nil {
+		t.Errorf("expected error for an invalid output format")
+	}
+}
+
+// fakeSeries answers GetSeriesDataContext from memory, failing the other
+// queries.
+type fakeSeries struct {
+	bcch.SeriesService
+	data map[string]bcch.SeriesDataResp
+}
+
+func (f fakeSeries) GetSeriesDataContext(ctx context.Context, seriesID, firstDate, lastDate string) (bcch.SeriesDataResp, error) {
+	data, ok := f.data[seriesID]
+	if !ok {
+		return bcch.SeriesDataResp{}, bcch.ErrUnknownSeries
+	}
+	return data, nil
+}
+
+func TestGetCmdSeriesService(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	var data bcch.SeriesDataResp
+	raw := `{"Series":{"seriesId":"FAKE.SERIES","descripEsp":"Serie de prueba","Obs":[{"indexDateString":"01-01-2024","value":"1.5","statusCode":"OK"}]}}`
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(orig func(*bcch.Client) bcch.SeriesService) { newSeriesService = orig }(newSeriesService)
+	newSeriesService = func(*bcch.Client) bcch.SeriesService {
+		return fakeSeries{data: map[string]bcch.SeriesDataResp{"FAKE.SERIES": data}}
+	}
+
+	out, err := executeCommand(t, srv, "get", "--series", "FAKE.SERIES")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "2024-01-01  1.5") {
+		t.Errorf("expected output from the series service, got:\n%s", out)
+	}
+	if n := srv.TotalRequests(); n != 0 {
+		t.Errorf("expected no requests to the BCCh server, got %v", n)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/fileio"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/output"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlags adds the --output and --out-file flags to cmd. File
+// formats handled by cmd itself are listed in fileFormats.
+func addOutputFlags(cmd *cobra.Command, fileFormats ...string) {
+	formats := make([]string, 0, len(output.Formats)+len(fileFormats))
+	for _, f := range output.Formats {
+		formats = append(formats, string(f))
+	}
+	formats = append(formats, fileFormats...)
+	cmd.Flags().StringP("output", "o", string(output.Table), "output format: "+strings.Join(formats, ", "))
+	cmd.Flags().String("out-file", "", "write the output to this file instead of the standard output")
+}
+
+// outputFormat returns the format selected with --output.
+func outputFormat(cmd *cobra.Command) (output.Format, error) {
+	outputFlag, _ := cmd.Flags().GetString("output")
+	return output.ParseFormat(outputFlag)
+}
+
+// humanOutput reports whether the output is a table printed to the
+// terminal, which is decorated with titles and blank lines.
+func humanOutput(cmd *cobra.Command, format output.Format) bool {
+	outFileFlag, _ := cmd.Flags().GetString("out-file")
+	return format == output.Table && outFileFlag == ""
+}
+
+// writeOutput writes data in format to the standard output or to the file
+// set with --out-file.
+func writeOutput(cmd *cobra.Command, format output.Format, data output.Data) error {
+	outFileFlag, _ := cmd.Flags().GetString("out-file")
+	if outFileFlag == "" {
+		return output.Write(cmd.OutOrStdout(), format, data)
+	}
+
+	f, err := fileio.Create(outFileFlag)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	if err := output.Write(f, format, data); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
+
+// seriesData returns series as a table with a date column and one column
+// per series, aligned on the observation dates.
+func seriesData(series []bcch.Series) output.Data {
+	data := output.Data{Columns: []string{"date"}}
+	for _, s := range series {
+		data.Columns = append(data.Columns, s.ID)
+	}
+	for _, row := range bcch.Align(series...) {
+		cells := make([]any, 0, len(row.Observations)+1)
+		cells = append(cells, row.Date.Format(dateLayout))
+		for _, obs := range row.Observations {
+			switch {
+			case obs == nil:
+				cells = append(cells, nil)
+			case obs.Missing:
+				cells = append(cells, math.NaN())
+			default:
+				cells = append(cells, obs.Value)
+			}
+		}
+		data.Rows = append(data.Rows, cells)
+	}
+	return data
+}
+
+// searchData returns the catalog entries as a table.
+func searchData(infos []bcch.SeriesInfo) output.Data {
+	data := output.Data{
+		Columns: []string{"series_id", "frequency", "spanish_title", "english_title", "first_observation", "last_observation"},
+	}
+	for _, info := range infos {
+		data.Rows = append(data.Rows, []any{
+			info.SeriesID,
+			info.FrequencyCode,
+			info.SpanishTitle,
+			info.EnglishTitle,
+			isoDate(info.FirstObservation),
+			isoDate(info.LastObservation),
+		})
+	}
+	return data
+}
+
+// isoDate converts a date in the layout of BCCh responses to YYYY-MM-DD,
+// leaving it untouched if it cannot be parsed.
+func isoDate(date string) string {
+	t, err := time.Parse(bcch.ObservationDateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format(dateLayout)
+}
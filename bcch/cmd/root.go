@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
 	"time"
 
-	bcchapi "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-api"
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
 	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/spinner"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
 	"github.com/spf13/cobra"
 )
 
@@ -20,9 +27,15 @@ const (
 	bcchCacheInterval = 24 * time.Hour
 )
 
+var _ bcch.Cache = (*bcchcache.Cache)(nil)
+
 type config struct {
-	bcchapiClient *bcchapi.Client
-	spinner       *spinner.Spinner
+	bcchClient *bcch.Client
+	// series answers the series queries of the commands, bcchClient unless
+	// replaced through newSeriesService.
+	series  bcch.SeriesService
+	cache   bcchcache.Cache
+	spinner *spinner.Spinner
 }
 
 type Set struct {
@@ -68,6 +81,7 @@ var rootCmd = &cobra.Command{
 	Long: `This CLI tool allows you to set credentials and search for available data series from the Banco Central de Chile API. 
 It allows the use of keywords to filter the whole list of available data series. 
 Every data series has their own ID which may be used on get command to retrieve its data.`,
+	SilenceUsage: true,
 }
 
 // SetVersion sets the version for the CLI
@@ -79,15 +93,123 @@ func SetVersion(v string) {
 // Execute executes the root command.
 func Execute(embeddedFS embed.FS) error {
 	EmbeddedFS = embeddedFS
-	return rootCmd.Execute()
+
+	// Ctrl+C cancels the context of the running command, aborting in-flight requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return execute(ctx)
+}
+
+// execute runs the root command and saves the cache stats afterwards, also
+// when the command fails, as failed commands record cache misses too.
+func execute(ctx context.Context) error {
+	err := rootCmd.ExecuteContext(ctx)
+	if flushErr := cfg.cache.FlushStats(); flushErr != nil {
+		log.Printf("warning: could not save cache stats: %v", flushErr)
+	}
+	return err
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().String("user", "", "BCCh user, overrides BCCH_USER and the saved profile")
+	rootCmd.PersistentFlags().String("password", "", "BCCh password, overrides BCCH_PASSWORD and the saved profile")
+	rootCmd.PersistentFlags().String("profile", bcch.DefaultProfile, "named set of saved credentials to use")
+	rootCmd.PersistentFlags().String("api-url", bcch.DefaultBaseURL, "base URL of the BCCh web service, e.g. a mirror or a proxy")
+	rootCmd.PersistentFlags().Bool("offline", false, "serve only cached responses and never reach the BCCh API")
+	rootCmd.PersistentFlags().Int("retries", 2, "number of retries for transient BCCh API failures")
+	rootCmd.PersistentFlags().Duration("retry-backoff", 500*time.Millisecond, "wait before the first retry, doubled on every retry")
+	rootCmd.PersistentFlags().Float64("rate-limit", 5, "maximum BCCh API requests per second, 0 disables the limit")
+	rootCmd.PersistentFlags().Int("rate-burst", 5, "maximum burst of BCCh API requests above the rate limit")
 }
 
 func initConfig() {
-	cfg.bcchapiClient = bcchapi.NewClient(clientTimeout, bcchCacheInterval)
+	profileFlag, _ := rootCmd.PersistentFlags().GetString("profile")
+	offlineFlag, _ := rootCmd.PersistentFlags().GetBool("offline")
+	apiURLFlag, _ := rootCmd.PersistentFlags().GetString("api-url")
+	retriesFlag, _ := rootCmd.PersistentFlags().GetInt("retries")
+	retryBackoffFlag, _ := rootCmd.PersistentFlags().GetDuration("retry-backoff")
+	rateLimitFlag, _ := rootCmd.PersistentFlags().GetFloat64("rate-limit")
+	rateBurstFlag, _ := rootCmd.PersistentFlags().GetInt("rate-burst")
+
+	cfg.cache = newCache()
+	cfg.bcchClient = bcch.NewClient(
+		clientTimeout,
+		&cfg.cache,
+		bcch.WithRateLimit(rateLimitFlag, rateBurstFlag),
+		bcch.WithBaseURL(apiURLFlag),
+		bcch.WithUserAgent("bcch/"+version),
+		bcch.WithLogger(log.Default()),
+	)
+	cfg.bcchClient.AuthConfig.Profile = profileFlag
+	cfg.bcchClient.Offline = offlineFlag
+	cfg.bcchClient.RetryPolicy.MaxAttempts = max(retriesFlag, 0) + 1
+	cfg.bcchClient.RetryPolicy.InitialBackoff = retryBackoffFlag
+	cfg.series = newSeriesService(cfg.bcchClient)
+}
+
+// newSeriesService returns the service used by the commands to query series.
+// Tests replace it to run the commands against mocks.
+var newSeriesService = func(client *bcch.Client) bcch.SeriesService {
+	return client
+}
+
+// loadCredentials loads the credentials into the client, in order of
+// precedence: --user/--password flags, BCCH_USER/BCCH_PASSWORD environment
+// variables and the saved profile, read from BCCH_CREDENTIALS_FILE if set.
+// Credentials are not needed in offline mode, so a missing file is not an
+// error there.
+func (cfg *config) loadCredentials() error {
+	userFlag, _ := rootCmd.PersistentFlags().GetString("user")
+	passwordFlag, _ := rootCmd.PersistentFlags().GetString("password")
+
+	auth := &cfg.bcchClient.AuthConfig
+	if userFlag != "" && passwordFlag != "" {
+		auth.User = userFlag
+		auth.Password = passwordFlag
+		return nil
+	}
+
+	if err := migrateLegacyCredentials(*auth, legacyCredentials, os.Stderr); err != nil {
+		return err
+	}
+	err := auth.Load()
+	if errors.Is(err, bcch.ErrCredentialsEncrypted) && stdinIsTerminal() {
+		passphrase, promptErr := promptSecret("Credentials passphrase: ")
+		if promptErr != nil {
+			return promptErr
+		}
+		auth.Store = bcch.NewPassphraseStore(passphrase)
+		err = auth.Load()
+	}
+	if userFlag != "" {
+		auth.User = userFlag
+	}
+	if passwordFlag != "" {
+		auth.Password = passwordFlag
+	}
+	if err != nil && (cfg.bcchClient.Offline || (auth.User != "" && auth.Password != "")) {
+		return nil
+	}
+	if errors.Is(err, bcch.ErrNoCredentials) {
+		return fmt.Errorf("%w, use 'setCredentials' to save them", err)
+	}
+	return err
+}
+
+// newCache returns a cache persisted in the user cache directory, falling
+// back to an in-memory cache when the directory cannot be used.
+func newCache() bcchcache.Cache {
+	dir, err := bcchcache.DefaultDir()
+	if err == nil {
+		var cache bcchcache.Cache
+		cache, err = bcchcache.NewFileCache(dir, bcchCacheInterval)
+		if err == nil {
+			return cache
+		}
+	}
+	log.Printf("warning: using in-memory cache: %v", err)
+	return bcchcache.NewCache(bcchCacheInterval)
 }
 
 func withSpinnerWrapper(s *spinner.Spinner, fn func(cmd *cobra.Command, args []string)) func(cmd *cobra.Command, args []string) {
@@ -98,3 +220,12 @@ func withSpinnerWrapper(s *spinner.Spinner, fn func(cmd *cobra.Command, args []s
 		fn(cmd, args)
 	}
 }
+
+func withSpinnerWrapperE(s *spinner.Spinner, fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	s = spinner.New(spinner.Config{})
+	return func(cmd *cobra.Command, args []string) error {
+		s.Start()
+		defer s.Stop()
+		return fn(cmd, args)
+	}
+}
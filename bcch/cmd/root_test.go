@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// executeCommand runs bcch with args against srv, with a fresh cache and
+// config directory, and returns its output.
+func executeCommand(t *testing.T, srv *bcchtest.Server, args ...string) (string, error) {
+	t.Helper()
+	return executeCommandInCache(t, srv, t.TempDir(), args...)
+}
+
+// executeCommandInCache is executeCommand with the cache in cacheDir.
+func executeCommandInCache(t *testing.T, srv *bcchtest.Server, cacheDir string, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv(bcchcache.EnvDir, cacheDir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	resetFlags(rootCmd)
+
+	args = append(args,
+		"--api-url", srv.URL,
+		"--user", bcchtest.User,
+		"--password", bcchtest.Password,
+		"--retry-backoff", "1ms",
+		"--rate-limit", "0",
+	)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	err := execute(context.Background())
+	return out.String(), err
+}
+
+// resetFlags restores the flags of cmd and its subcommands to their
+// defaults, as the commands are shared between test runs.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
 	"github.com/spf13/cobra"
 )
 
@@ -13,27 +14,26 @@ var searchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "Search the whole list of available data series to be queried.",
 	Long:  `Every data series has their own ID which may be used on get command to retrieve its data.`,
-	Run: withSpinnerWrapper(cfg.spinner, func(cmd *cobra.Command, args []string) {
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
 		predefinedSetsFlag, _ := cmd.Flags().GetBool("predefined-sets")
 
 		if predefinedSetsFlag {
-			fmt.Println("Available predefined sets for visualization:")
+			fmt.Fprintln(cmd.OutOrStdout(), "Available predefined sets for visualization:")
 			setNames := slices.Sorted(maps.Keys(AvailableSetsSeries))
 			for _, setName := range setNames {
 				set := AvailableSetsSeries[setName]
-				fmt.Printf("- %s: %s\n", setName, set.Description)
+				fmt.Fprintf(cmd.OutOrStdout(), "- %s: %s\n", setName, set.Description)
 			}
-			return
+			return nil
 		}
 
-		err := cfg.bcchapiClient.AuthConfig.Load()
+		err := cfg.loadCredentials()
 		if err != nil {
-			fmt.Printf("error loading credentials: %v\n", err)
-			return
+			return fmt.Errorf("error loading credentials: %w", err)
 		}
-		creds := cfg.bcchapiClient.AuthConfig
-		if creds.User == "" || creds.Password == "" {
-			fmt.Println("you need to first set your BCCH credentials to use this command, see 'help' for details")
+		creds := cfg.bcchClient.AuthConfig
+		if !cfg.bcchClient.Offline && (creds.User == "" || creds.Password == "") {
+			fmt.Fprintln(cmd.OutOrStdout(), "you need to first set your BCCH credentials to use this command, see 'help' for details")
 		}
 
 		frequencyFlag, _ := cmd.Flags().GetString("frequency")
@@ -42,29 +42,33 @@ var searchCmd = &cobra.Command{
 
 		validFrequencies := []string{"DAILY", "MONTHLY", "QUARTERLY", "ANNUAL"}
 		if !slices.Contains(validFrequencies, frequencyFlag) {
-			fmt.Println("--frequency must be one of: DAILY, MONTHLY, QUARTERLY, ANNUAL.")
-			return
+			fmt.Fprintln(cmd.OutOrStdout(), "--frequency must be one of: DAILY, MONTHLY, QUARTERLY, ANNUAL.")
+			return nil
 		}
 
-		availableSeries, _ := cfg.bcchapiClient.GetAvailableSeries(frequencyFlag)
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
 
-		if availableSeries.Codigo != 0 {
-			fmt.Println(availableSeries.Descripcion)
+		availableSeries, err := cfg.series.GetAvailableSeriesContext(cmd.Context(), frequencyFlag)
+		if err != nil {
+			// placeholder for spinner last symbol
+			fmt.Fprintln(cmd.OutOrStdout())
+			return fmt.Errorf("error searching series: %w", err)
+		}
+		if humanOutput(cmd, format) {
+			// placeholder for spinner last symbol
+			fmt.Fprintln(cmd.OutOrStdout())
 		}
-		// placeholder for spinner last symbol
-		fmt.Println("")
 
+		infos := availableSeries.SeriesInfos
 		if keywordFlag != "" {
-			for _, serie := range availableSeries.SeriesInfos {
-				if strings.Contains(serie.SpanishTitle, keywordFlag) {
-					fmt.Printf("- %v: %v\n", serie.SeriesID, serie.SpanishTitle)
-				}
-			}
-		} else {
-			for _, serie := range availableSeries.SeriesInfos {
-				fmt.Printf("- %v: %v\n", serie.SeriesID, serie.SpanishTitle)
-			}
+			infos = slices.DeleteFunc(slices.Clone(infos), func(info bcch.SeriesInfo) bool {
+				return !strings.Contains(info.SpanishTitle, keywordFlag)
+			})
 		}
+		return writeOutput(cmd, format, searchData(infos))
 	}),
 }
 
@@ -73,4 +77,5 @@ func init() {
 	searchCmd.Flags().StringP("frequency", "f", "", "Frequency of the data: DAILY, MONTHLY, QUARTERLY, or ANNUAL")
 	searchCmd.Flags().StringP("keyword", "k", "", "Keyword to be used to filter the list of series")
 	searchCmd.Flags().Bool("predefined-sets", false, "List available predefined sets for visualization")
+	addOutputFlags(searchCmd)
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+func TestSearchCmd(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	out, err := executeCommand(t, srv, "search", "--frequency", "MONTHLY", "--keyword", "IPC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "F074.IPC.VAR.Z.Z.C.M  MONTHLY    IPC General") {
+		t.Errorf("expected output to list the CPI series, got:\n%s", out)
+	}
+	if strings.Contains(out, "F049.DES.TAS.INE.10.M") {
+		t.Errorf("expected keyword to filter out other series, got:\n%s", out)
+	}
+}
+
+func TestSearchCmdJSON(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	out, err := executeCommand(t, srv, "search", "--frequency", "MONTHLY", "--output", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var infos []map[string]string
+	if err := json.Unmarshal([]byte(out), &infos); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 series, got %v", len(infos))
+	}
+	if infos[0]["series_id"] == "" || infos[0]["first_observation"] != "2023-01-01" {
+		t.Errorf("expected series_id and ISO dates, got %v", infos[0])
+	}
+}
@@ -1,35 +1,172 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // BCCH does not have any login feature, but the url for api requests uses the credentials.
 var setCredentialsCmd = &cobra.Command{
 	Use:   "setCredentials",
 	Short: "set credentials to be used to retrieve data from BCCh API",
-	Long: `It saves the credentials in a local file.
-    The local file is generated in the same folder where the cmd is used`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `It saves the credentials in the user config directory (e.g. ~/.config/bcch/credentials.json on Linux),
+    readable only by the current user. Use --profile to keep several sets of credentials.
+    Credentials saved by older versions in the current folder are moved there, and the old file
+    removed, the first time credentials are loaded or saved. The old file is left in place, with
+    a warning, when the default profile already holds other credentials.
+
+    When --password is omitted the password is prompted without echo, or read from
+    stdin with --password-stdin, so it does not end up in the shell history.
+
+    With --encrypt the file is encrypted with AES-GCM, using a key derived from a
+    passphrase (BCCH_PASSPHRASE or prompted) or a random key file, generated if missing.
+    The default key file is found automatically; a custom one has to be set in BCCH_KEY_FILE.
+
+    Example:
+        bcch setCredentials -u myUser --verify
+        echo "$BCCH_PASSWORD" | bcch setCredentials -u myUser --password-stdin
+        bcch setCredentials -u myUser --encrypt passphrase
+        bcch setCredentials -u myUser --encrypt keyfile`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		userFlag, _ := cmd.Flags().GetString("user")
 		passwordFlag, _ := cmd.Flags().GetString("password")
-		cfg.bcchapiClient.AuthConfig.User = userFlag
-		cfg.bcchapiClient.AuthConfig.Password = passwordFlag
+		passwordStdinFlag, _ := cmd.Flags().GetBool("password-stdin")
+		encryptFlag, _ := cmd.Flags().GetString("encrypt")
+		keyFileFlag, _ := cmd.Flags().GetString("key-file")
+		verifyFlag, _ := cmd.Flags().GetBool("verify")
+
+		if passwordFlag == "" {
+			var err error
+			passwordFlag, err = readPassword(cmd.InOrStdin(), passwordStdinFlag)
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+		}
+		if passwordFlag == "" {
+			return errors.New("password cannot be empty")
+		}
+
+		store, err := credentialStore(cmd.InOrStdin(), encryptFlag, keyFileFlag)
+		if err != nil {
+			return fmt.Errorf("failed to set up encryption: %w", err)
+		}
 
-		err := cfg.bcchapiClient.AuthConfig.Save()
+		cfg.bcchClient.AuthConfig.User = userFlag
+		cfg.bcchClient.AuthConfig.Password = passwordFlag
+		cfg.bcchClient.AuthConfig.Store = store
+
+		if verifyFlag {
+			if err := verifyCredentials(cmd.Context(), "check the user and password"); err != nil {
+				return fmt.Errorf("credentials not saved: %w", err)
+			}
+		}
+
+		if err := migrateLegacyCredentials(cfg.bcchClient.AuthConfig, legacyCredentials, cmd.ErrOrStderr()); err != nil {
+			return fmt.Errorf("failed to migrate credentials: %w", err)
+		}
+		err = cfg.bcchClient.AuthConfig.Save()
 		if err != nil {
-			fmt.Printf("failed to save credentials: %v\n", err)
-			return
+			return fmt.Errorf("failed to save credentials: %w", err)
 		}
-		fmt.Println("saved credentials!")
+		fmt.Printf("saved credentials for profile %q!\n", cfg.bcchClient.AuthConfig.Profile)
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(setCredentialsCmd)
 	setCredentialsCmd.Flags().StringP("user", "u", "", "user for for bcch")
-	setCredentialsCmd.Flags().StringP("password", "p", "", "password for bcch")
-	setCredentialsCmd.MarkFlagsRequiredTogether("user", "password")
+	setCredentialsCmd.Flags().StringP("password", "p", "", "password for bcch, prompted when omitted")
+	setCredentialsCmd.Flags().Bool("password-stdin", false, "read the password from stdin")
+	setCredentialsCmd.Flags().String("encrypt", "", "encrypt the credentials file with a 'passphrase' or a 'keyfile'")
+	setCredentialsCmd.Flags().String("key-file", "", "key file used with --encrypt keyfile (default: credentials.key in the config dir)")
+	setCredentialsCmd.Flags().Bool("verify", false, "check the credentials against the BCCh API before saving them")
+	setCredentialsCmd.MarkFlagRequired("user")
+	setCredentialsCmd.MarkFlagsMutuallyExclusive("password", "password-stdin")
+}
+
+// readPassword reads the password from the first line of in when fromStdin
+// is set, or prompts for it without echo when stdin is a terminal.
+func readPassword(in io.Reader, fromStdin bool) (string, error) {
+	if fromStdin {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	if !stdinIsTerminal() {
+		return "", errors.New("stdin is not a terminal, use --password or --password-stdin")
+	}
+	return promptSecret("Password: ")
+}
+
+// credentialStore returns the store used to save the credentials for the
+// given --encrypt mode, nil to use the default one.
+func credentialStore(in io.Reader, mode, keyFile string) (bcch.CredentialStore, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case "passphrase":
+		passphrase := os.Getenv(bcch.EnvPassphrase)
+		if passphrase == "" {
+			if !stdinIsTerminal() {
+				return nil, fmt.Errorf("stdin is not a terminal, set %s", bcch.EnvPassphrase)
+			}
+			var err error
+			if passphrase, err = promptSecret("Passphrase: "); err != nil {
+				return nil, err
+			}
+			confirm, err := promptSecret("Repeat passphrase: ")
+			if err != nil {
+				return nil, err
+			}
+			if confirm != passphrase {
+				return nil, errors.New("passphrases do not match")
+			}
+		}
+		if passphrase == "" {
+			return nil, errors.New("passphrase cannot be empty")
+		}
+		return bcch.NewPassphraseStore(passphrase), nil
+	case "keyfile":
+		if keyFile == "" {
+			var err error
+			if keyFile, err = bcch.DefaultKeyFilePath(); err != nil {
+				return nil, err
+			}
+		}
+		if err := bcch.CreateKeyFile(keyFile); err != nil {
+			return nil, fmt.Errorf("error creating key file: %w", err)
+		}
+		fmt.Printf("using key file %s\n", keyFile)
+		return bcch.NewKeyFileStore(keyFile), nil
+	default:
+		return nil, fmt.Errorf("--encrypt must be one of: passphrase, keyfile")
+	}
+}
+
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) // #nosec G115 -- file descriptors fit in an int
+}
+
+// promptSecret prompts on stderr and reads a line from the terminal
+// without echo.
+func promptSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd())) // #nosec G115 -- file descriptors fit in an int
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
 }
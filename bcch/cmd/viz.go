@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -15,14 +17,14 @@ import (
 	"strings"
 	"time"
 
-	bcchapi "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-api"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
 type OutputSetData struct {
-	Description string                            `json:"description"`
-	SeriesData  map[string]bcchapi.SeriesDataResp `json:"seriesData"`
+	Description string                         `json:"description"`
+	SeriesData  map[string]bcch.SeriesDataResp `json:"seriesData"`
 }
 
 var vizCmd = &cobra.Command{
@@ -40,11 +42,10 @@ To check which set of series are available in this version,
 take a look at 'search --predefined-sets'
 		`,
 	Example: "bcch viz",
-	Run: withSpinnerWrapper(cfg.spinner, func(cmd *cobra.Command, args []string) {
-		err := cfg.bcchapiClient.AuthConfig.Load()
+	RunE: withSpinnerWrapperE(cfg.spinner, func(cmd *cobra.Command, args []string) error {
+		err := cfg.loadCredentials()
 		if err != nil {
-			fmt.Printf("error loading credentials: %v\n", err)
-			return
+			return fmt.Errorf("error loading credentials: %w", err)
 		}
 
 		setNameFlag, _ := cmd.Flags().GetString("set")
@@ -57,9 +58,10 @@ take a look at 'search --predefined-sets'
 		}
 
 		// can later use go for --detached mode
-		if err := cfg.StartVizServer(EmbeddedFS, portFlag); err != nil {
-			log.Fatalf("viz server error: %v", err)
+		if err := cfg.StartVizServer(cmd.Context(), EmbeddedFS, portFlag); err != nil {
+			return fmt.Errorf("viz server error: %w", err)
 		}
+		return nil
 	}),
 }
 
@@ -70,18 +72,25 @@ func init() {
 	vizCmd.Flags().StringP("port", "p", "49966", "Port for the visualization server")
 }
 
-func (cfg *config) fetchSeries(setName string, set Set, maxConcurrency int) map[string]OutputSetData {
-	seriesSetData, seriesSetErrors := cfg.bcchapiClient.GetMultipleSeriesData(
+// fetchSeries fetches the series of set. Series that could not be fetched
+// are reported and left out of the returned data, along with an error
+// joining their causes.
+func (cfg *config) fetchSeries(ctx context.Context, setName string, set Set, maxConcurrency int) (map[string]OutputSetData, error) {
+	results, err := cfg.series.GetMultipleSeriesDataContext(
+		ctx,
 		set.SeriesNames,
 		"",
 		"",
-		&bcchapi.FetchOptions{MaxConcurrency: maxConcurrency},
+		&bcch.FetchOptions{MaxConcurrency: maxConcurrency},
 	)
 
-	for _, err := range seriesSetErrors {
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+	seriesSetData := make(map[string]bcch.SeriesDataResp, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("Error: %v\n", result.Err)
+			continue
 		}
+		seriesSetData[result.SeriesID] = result.Data
 	}
 
 	outputSetData := map[string]OutputSetData{
@@ -91,7 +100,7 @@ func (cfg *config) fetchSeries(setName string, set Set, maxConcurrency int) map[
 		},
 	}
 
-	return outputSetData
+	return outputSetData, err
 }
 
 func (cfg *config) generateMatplotlibCharts(setName string, setData map[string]OutputSetData) error {
@@ -143,7 +152,9 @@ func (cfg *config) generateMatplotlibCharts(setName string, setData map[string]O
 	return nil
 }
 
-func (cfg *config) StartVizServer(embeddedFS embed.FS, port string) error {
+// StartVizServer serves the dashboard until ctx is done, then shuts the
+// server down gracefully and returns the error of ctx.
+func (cfg *config) StartVizServer(ctx context.Context, embeddedFS embed.FS, port string) error {
 	// Fetch data for chart generation
 	setName := "EMPLOYMENT" // Default set
 	set, ok := AvailableSetsSeries[setName]
@@ -151,7 +162,10 @@ func (cfg *config) StartVizServer(embeddedFS embed.FS, port string) error {
 		return fmt.Errorf("default set %q not found", setName)
 	}
 
-	setData := cfg.fetchSeries(setName, set, 3)
+	setData, err := cfg.fetchSeries(ctx, setName, set, 3)
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
 
 	// Generate matplotlib charts (optional - graceful fallback if it fails)
 	if err := cfg.generateMatplotlibCharts(setName, setData); err != nil {
@@ -161,7 +175,11 @@ func (cfg *config) StartVizServer(embeddedFS embed.FS, port string) error {
 
 	url := "http://localhost:" + port + "/"
 	go func() {
-		time.Sleep(2 * time.Second)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
 		if err := browser.OpenURL(url); err != nil {
 			log.Printf("Warning: Could not open browser automatically: %v", err)
 		}
@@ -195,7 +213,19 @@ func (cfg *config) StartVizServer(embeddedFS embed.FS, port string) error {
 	// API endpoints
 	mux.HandleFunc("GET /api/sets/{set}", cfg.handlerSetGet)
 
-	return server.ListenAndServe()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) // #nosec G104 -- server is exiting anyway
+	}()
+
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		// only closed by the shutdown above, report why
+		return ctx.Err()
+	}
+	return err
 }
 
 func (cfg *config) handlerSetGet(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +247,11 @@ func (cfg *config) handlerSetGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	setData := cfg.fetchSeries(setName, set, 3)
+	setData, err := cfg.fetchSeries(r.Context(), setName, set, 3)
+	if err != nil && r.Context().Err() != nil {
+		// the client went away, there is no one to answer
+		return
+	}
 
 	_ = respondWithJSON(w, http.StatusOK, responseBody{ // #nosec G104 -- HTTP handler, cannot handle response errors
 		Set: setData,
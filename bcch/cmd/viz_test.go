@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+)
+
+// setSeries answers multiple-series fetches from memory, failing the series
+// it has no data for.
+type setSeries struct {
+	bcch.SeriesService
+	data map[string]bcch.SeriesDataResp
+}
+
+func (s setSeries) GetMultipleSeriesDataContext(ctx context.Context, seriesIDs []string, firstDate, lastDate string, opts *bcch.FetchOptions) ([]bcch.SeriesResult, error) {
+	results := make([]bcch.SeriesResult, len(seriesIDs))
+	var errs []error
+	for i, id := range seriesIDs {
+		results[i] = bcch.SeriesResult{Index: i, SeriesID: id}
+		data, ok := s.data[id]
+		if !ok {
+			results[i].Status = bcch.FetchFailed
+			results[i].Err = bcch.ErrUnknownSeries
+			errs = append(errs, results[i].Err)
+			continue
+		}
+		results[i].Data = data
+	}
+	return results, errors.Join(errs...)
+}
+
+func TestHandlerSetGet(t *testing.T) {
+	var data bcch.SeriesDataResp
+	raw := `{"Series":{"seriesId":"F073.TCO.PRE.Z.D","descripEsp":"Tipo de cambio","Obs":[{"indexDateString":"02-01-2024","value":"884.59","statusCode":"OK"}]}}`
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(orig bcch.SeriesService) { cfg.series = orig }(cfg.series)
+	cfg.series = setSeries{data: map[string]bcch.SeriesDataResp{"F073.TCO.PRE.Z.D": data}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/sets/{set}", cfg.handlerSetGet)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("known set", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/api/sets/employment")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, resp.StatusCode)
+		}
+
+		var body struct {
+			Set map[string]OutputSetData
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("expected a JSON body, got %v", err)
+		}
+		set, ok := body.Set["EMPLOYMENT"]
+		if !ok {
+			t.Fatalf("expected the EMPLOYMENT set, got %v", body.Set)
+		}
+		if set.Description != AvailableSetsSeries["EMPLOYMENT"].Description {
+			t.Errorf("expected the set description, got %q", set.Description)
+		}
+		// series that could not be fetched are left out
+		if len(set.SeriesData) != 1 || set.SeriesData["F073.TCO.PRE.Z.D"].Series.SeriesID != "F073.TCO.PRE.Z.D" {
+			t.Errorf("expected only F073.TCO.PRE.Z.D, got %v", set.SeriesData)
+		}
+	})
+
+	t.Run("unknown set", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/api/sets/unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %v, got %v", http.StatusBadRequest, resp.StatusCode)
+		}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error != "set not found" {
+			t.Errorf("expected error %q, got %q (%v)", "set not found", body.Error, err)
+		}
+	})
+}
+
+func TestStartVizServerInterrupted(t *testing.T) {
+	defer func(orig bcch.SeriesService) { cfg.series = orig }(cfg.series)
+	cfg.series = setSeries{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	err := cfg.StartVizServer(ctx, EmbeddedFS, "0")
+	if got := ExitCode(err); got != ExitInterrupted {
+		t.Errorf("expected exit code %v, got %v (%v)", ExitInterrupted, got, err)
+	}
+}
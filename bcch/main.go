@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/iferdel/chile-economic-indexes-cli/v3/bcch/cmd"
 )
@@ -13,5 +14,7 @@ var version = "dev"
 
 func main() {
 	cmd.SetVersion(version)
-	cmd.Execute(PublicEmbeddedFS) // #nosec G104
+	if err := cmd.Execute(PublicEmbeddedFS); err != nil {
+		os.Exit(cmd.ExitCode(err))
+	}
 }
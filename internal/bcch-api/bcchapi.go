@@ -1,5 +0,0 @@
-package bcchapi
-
-const (
-	baseURL = "https://si3.bcentral.cl/SieteRestWS/"
-)
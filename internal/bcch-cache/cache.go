@@ -1,14 +1,40 @@
 package bcchcache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
+const entryExt = ".json"
+
+// Cache keeps responses in memory and, when created with NewFileCache,
+// persists them on disk so they survive between runs of the CLI.
+// Entries older than the cache interval are treated as misses on read.
 type Cache struct {
-	cache map[string]cacheEntry
-	mux   *sync.Mutex
+	cache    map[string]cacheEntry
+	mux      *sync.Mutex
+	interval time.Duration
+	dir      string
+	stats    *Stats
+}
+
+// Entry describes a cached response without its content.
+type Entry struct {
+	Key       string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Age returns how long ago the entry was stored.
+func (e Entry) Age() time.Duration {
+	return time.Now().UTC().Sub(e.CreatedAt)
 }
 
 type cacheEntry struct {
@@ -16,29 +42,71 @@ type cacheEntry struct {
 	value     []byte
 }
 
+// fileEntry is the on-disk representation of a cache entry.
+type fileEntry struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+	Value     []byte    `json:"value"`
+}
+
+// NewCache creates an in-memory cache whose entries expire after interval.
 func NewCache(interval time.Duration) Cache {
-	c := Cache{
-		cache: make(map[string]cacheEntry),
-		mux:   &sync.Mutex{},
+	return Cache{
+		cache:    make(map[string]cacheEntry),
+		mux:      &sync.Mutex{},
+		interval: interval,
+		stats:    &Stats{},
 	}
-	go c.reapLoop(interval)
-	return c
 }
 
-func (c *Cache) Add(key string, value []byte) error {
-	c.mux.Lock()
-	defer c.mux.Unlock()
+// NewFileCache creates a cache backed by files in dir, creating the
+// directory if needed. Entries expire after interval.
+func NewFileCache(dir string, interval time.Duration) (Cache, error) {
+	if dir == "" {
+		return Cache{}, errors.New("cannot use empty cache directory")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Cache{}, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	c := NewCache(interval)
+	c.dir = dir
+	return c, nil
+}
+
+// EnvDir overrides the directory returned by DefaultDir.
+const EnvDir = "BCCH_CACHE_DIR"
+
+// DefaultDir returns the directory used for the bcch cache inside the
+// user cache directory, or the one set in EnvDir.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bcch"), nil
+}
 
+func (c *Cache) Add(key string, value []byte) error {
 	if key == "" {
 		return fmt.Errorf("cannot add empty key")
 	}
 
-	c.cache[key] = cacheEntry{
-		createdAt: time.Now(),
+	entry := cacheEntry{
+		createdAt: time.Now().UTC(),
 		value:     value,
 	}
 
-	return nil
+	c.mux.Lock()
+	c.cache[key] = entry
+	c.mux.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+	return c.writeFile(key, entry)
 }
 
 func (c *Cache) Get(key string) ([]byte, bool) {
@@ -46,25 +114,162 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 	defer c.mux.Unlock()
 
 	entry, ok := c.cache[key]
-	return entry.value, ok
+	if !ok && c.dir != "" {
+		entry, ok = c.readFile(key)
+		if ok {
+			c.cache[key] = entry
+		}
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	if c.expired(entry) {
+		delete(c.cache, key)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	return entry.value, true
 }
 
-func (c *Cache) reapLoop(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-	for range ticker.C {
-		c.reap(interval) // NewTicker ticks whenever the interval is met
+// GetStale returns the value stored under key even if it has expired,
+// along with the time it was stored.
+func (c *Cache) GetStale(key string) ([]byte, time.Time, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	entry, ok := c.cache[key]
+	if !ok && c.dir != "" {
+		entry, ok = c.readFile(key)
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, time.Time{}, false
 	}
+	c.stats.Hits++
+	return entry.value, entry.createdAt, true
+}
+
+// Interval returns the duration after which entries expire.
+func (c *Cache) Interval() time.Duration {
+	return c.interval
 }
 
-func (c *Cache) reap(interval time.Duration) {
+// Dir returns the directory backing the cache, empty for in-memory caches.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Entries lists every stored entry, including expired ones.
+func (c *Cache) Entries() ([]Entry, error) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
-	currentTime := time.Now().UTC()
-	for key, entry := range c.cache {
-		if currentTime.Sub(entry.createdAt) > interval {
-			delete(c.cache, key)
+	if c.dir == "" {
+		entries := make([]Entry, 0, len(c.cache))
+		for key, entry := range c.cache {
+			entries = append(entries, Entry{
+				Key:       key,
+				CreatedAt: entry.createdAt,
+				Size:      int64(len(entry.value)),
+			})
 		}
+		return entries, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*"+entryExt))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		if filepath.Base(file) == statsFile {
+			continue
+		}
+		dat, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			// entry removed by another process in the meantime
+			continue
+		}
+		var fe fileEntry
+		if err := json.Unmarshal(dat, &fe); err != nil || fe.Key == "" {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:       fe.Key,
+			CreatedAt: fe.CreatedAt,
+			Size:      int64(len(fe.Value)),
+		})
+	}
+	return entries, nil
+}
+
+// Remove deletes the entry stored under key. Removing a missing key is not
+// an error.
+func (c *Cache) Remove(key string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.cache, key)
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing cache file: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) expired(entry cacheEntry) bool {
+	return time.Now().UTC().Sub(entry.createdAt) > c.interval
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+entryExt)
+}
+
+func (c *Cache) readFile(key string) (cacheEntry, bool) {
+	dat, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var fe fileEntry
+	if err := json.Unmarshal(dat, &fe); err != nil || fe.Key != key {
+		return cacheEntry{}, false
 	}
+	return cacheEntry{createdAt: fe.CreatedAt, value: fe.Value}, true
+}
+
+// writeFile stores the entry in a temporary file and renames it into place,
+// so concurrent readers (including other bcch processes) never observe a
+// partially written entry.
+func (c *Cache) writeFile(key string, entry cacheEntry) error {
+	data, err := json.Marshal(fileEntry{
+		Key:       key,
+		CreatedAt: entry.createdAt,
+		Value:     entry.value,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // #nosec G104 -- no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("error saving cache file: %w", err)
+	}
+	return nil
 }
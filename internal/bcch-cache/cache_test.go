@@ -92,3 +92,89 @@ func TestReapFail(t *testing.T) {
 		t.Errorf("expected to find %v: %v", key, retrievedData)
 	}
 }
+
+func TestFileCachePersists(t *testing.T) {
+	dir := t.TempDir()
+	interval := 5 * time.Second
+
+	c, err := NewFileCache(dir, interval)
+	if err != nil {
+		t.Fatalf("unexpected error creating file cache: %v", err)
+	}
+	key, value := "function=GetSeries&timeseries=UF", []byte("test")
+	if err := c.Add(key, value); err != nil {
+		t.Fatalf("unexpected error adding key %v: %v", key, err)
+	}
+
+	// a new cache over the same directory simulates a later run
+	other, err := NewFileCache(dir, interval)
+	if err != nil {
+		t.Fatalf("unexpected error creating file cache: %v", err)
+	}
+	got, ok := other.Get(key)
+	if !ok {
+		t.Fatalf("expected key %v to be found on disk", key)
+	}
+	if string(got) != string(value) {
+		t.Errorf("expected value %v, but got %v value", value, got)
+	}
+}
+
+func TestFileCacheExpires(t *testing.T) {
+	dir := t.TempDir()
+	interval := 1 * time.Second
+
+	c, err := NewFileCache(dir, interval)
+	if err != nil {
+		t.Fatalf("unexpected error creating file cache: %v", err)
+	}
+	key := "function=GetSeries&timeseries=UF"
+	c.Add(key, []byte("test"))
+
+	time.Sleep(interval + time.Millisecond)
+
+	other, err := NewFileCache(dir, interval)
+	if err != nil {
+		t.Fatalf("unexpected error creating file cache: %v", err)
+	}
+	if retrievedData, ok := other.Get(key); ok {
+		t.Errorf("not expected to find %v: %v", key, retrievedData)
+	}
+}
+
+func TestEntriesRemove(t *testing.T) {
+	c, err := NewFileCache(t.TempDir(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error creating file cache: %v", err)
+	}
+	c.Add("function=GetSeries&timeseries=UF", []byte("uf"))
+	c.Add("function=GetSeries&timeseries=IPC", []byte("ipc"))
+	c.Get("function=GetSeries&timeseries=UF")
+	c.Get("function=GetSeries&timeseries=DOLAR")
+	if err := c.FlushStats(); err != nil {
+		t.Fatalf("unexpected error flushing stats: %v", err)
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error listing entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", len(entries))
+	}
+
+	if err := c.Remove("function=GetSeries&timeseries=UF"); err != nil {
+		t.Fatalf("unexpected error removing entry: %v", err)
+	}
+	if _, ok := c.Get("function=GetSeries&timeseries=UF"); ok {
+		t.Errorf("not expected to find removed entry")
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatalf("unexpected error reading stats: %v", err)
+	}
+	if stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("expected 1 hit and 2 misses, got %+v", stats)
+	}
+}
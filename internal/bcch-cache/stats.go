@@ -0,0 +1,143 @@
+package bcchcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	statsFile     = "stats.json"
+	statsLockFile = "stats.lock"
+	staleLockAge  = 10 * time.Second
+)
+
+// Stats holds the hit/miss counters of a cache.
+type Stats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// HitRatio returns the share of lookups served from the cache.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Stats returns the counters accumulated on disk plus the ones of the
+// current process that have not been flushed yet.
+func (c *Cache) Stats() (Stats, error) {
+	c.mux.Lock()
+	current := *c.stats
+	c.mux.Unlock()
+
+	if c.dir == "" {
+		return current, nil
+	}
+	stored, err := c.readStats()
+	if err != nil {
+		return current, err
+	}
+	stored.Hits += current.Hits
+	stored.Misses += current.Misses
+	return stored, nil
+}
+
+// FlushStats adds the counters of the current process to the ones stored
+// on disk and resets them. It is a no-op for in-memory caches.
+func (c *Cache) FlushStats() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	c.mux.Lock()
+	current := *c.stats
+	*c.stats = Stats{}
+	c.mux.Unlock()
+
+	if current.Hits == 0 && current.Misses == 0 {
+		return nil
+	}
+
+	unlock, err := c.lockStats()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, err := c.readStats()
+	if err != nil {
+		return err
+	}
+	stored.Hits += current.Hits
+	stored.Misses += current.Misses
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, statsFile), data, 0600)
+}
+
+// ResetStats clears the counters, both in memory and on disk.
+func (c *Cache) ResetStats() error {
+	c.mux.Lock()
+	*c.stats = Stats{}
+	c.mux.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+	err := os.Remove(filepath.Join(c.dir, statsFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (c *Cache) readStats() (Stats, error) {
+	var stats Stats
+	dat, err := os.ReadFile(filepath.Join(c.dir, statsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("error reading cache stats: %w", err)
+	}
+	if err := json.Unmarshal(dat, &stats); err != nil {
+		return Stats{}, fmt.Errorf("error during unmarshal of cache stats (JSON): %w", err)
+	}
+	return stats, nil
+}
+
+// lockStats serialises stats updates between bcch processes sharing the
+// cache directory. Locks left behind by a crashed process are taken over
+// once they are older than staleLockAge.
+func (c *Cache) lockStats() (func(), error) {
+	lockPath := filepath.Join(c.dir, statsLockFile)
+	deadline := time.Now().Add(2 * staleLockAge)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) // #nosec G304 -- path built from the cache dir
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error locking cache stats: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for cache stats lock")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
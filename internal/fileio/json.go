@@ -3,6 +3,7 @@ package fileio
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,13 +16,18 @@ func SaveSeriesToJSON(payload any, filename string) error {
 		return errors.New("invalid filename: path traversal or absolute path not allowed")
 	}
 
-	file, err := os.Create(cleanPath)
+	file, err := Create(cleanPath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	encoder := json.NewEncoder(file)
+	return WriteJSON(file, payload)
+}
+
+// WriteJSON writes payload to w as indented JSON.
+func WriteJSON(w io.Writer, payload any) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(payload); err != nil {
@@ -29,3 +35,13 @@ func SaveSeriesToJSON(payload any, filename string) error {
 	}
 	return nil
 }
+
+// Create creates or truncates the file chosen by the user to write output
+// to, creating its parent directory if needed.
+func Create(filename string) (*os.File, error) {
+	cleanPath := filepath.Clean(filename)
+	if err := os.MkdirAll(filepath.Dir(cleanPath), 0750); err != nil {
+		return nil, err
+	}
+	return os.Create(cleanPath) // #nosec G304 -- output file chosen by the user
+}
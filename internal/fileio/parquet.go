@@ -0,0 +1,151 @@
+package fileio
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Layout is the shape of a table of several series.
+type Layout string
+
+const (
+	// LongLayout has one row per observation with the series_id, date,
+	// value and status columns.
+	LongLayout Layout = "long"
+	// WideLayout has one row per date with a date column and one value
+	// column per series, aligned with bcch.Align.
+	WideLayout Layout = "wide"
+)
+
+// ParseLayout parses the name of a layout.
+func ParseLayout(s string) (Layout, error) {
+	switch l := Layout(s); l {
+	case LongLayout, WideLayout:
+		return l, nil
+	}
+	return "", fmt.Errorf("invalid layout %q, must be long or wide", s)
+}
+
+// longRow is a row of the long layout. Dates use the DATE logical type,
+// counted in days since the Unix epoch.
+type longRow struct {
+	SeriesID string   `parquet:"series_id"`
+	Date     int32    `parquet:"date,date"`
+	Value    *float64 `parquet:"value,optional"`
+	Status   string   `parquet:"status"`
+}
+
+// SaveSeriesToParquet writes series to filename as a Snappy compressed
+// Parquet file in the given layout.
+func SaveSeriesToParquet(series []bcch.Series, layout Layout, filename string) error {
+	file, err := Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := WriteSeriesParquet(file, series, layout); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteSeriesParquet writes series to w as a Parquet file in the given
+// layout. Dates use the DATE logical type and values are DOUBLE columns,
+// null where BCCh has no value and, in the wide layout, on dates without an
+// observation of the series.
+func WriteSeriesParquet(w io.Writer, series []bcch.Series, layout Layout) error {
+	switch layout {
+	case LongLayout:
+		return writeParquetLong(w, series)
+	case WideLayout:
+		return writeParquetWide(w, series)
+	}
+	return fmt.Errorf("invalid layout %q", layout)
+}
+
+func writeParquetLong(w io.Writer, series []bcch.Series) error {
+	writer := parquet.NewGenericWriter[longRow](w, parquet.Compression(&parquet.Snappy))
+	for _, s := range series {
+		rows := make([]longRow, 0, len(s.Observations))
+		for _, obs := range s.Observations {
+			row := longRow{
+				SeriesID: s.ID,
+				Date:     epochDays(obs.Date),
+				Status:   obs.Status.String(),
+			}
+			if !obs.Missing {
+				value := obs.Value
+				row.Value = &value
+			}
+			rows = append(rows, row)
+		}
+		if _, err := writer.Write(rows); err != nil {
+			return fmt.Errorf("error writing parquet rows of %s: %w", s.ID, err)
+		}
+	}
+	return writer.Close()
+}
+
+func writeParquetWide(w io.Writer, series []bcch.Series) error {
+	group := orderedGroup{
+		Group: parquet.Group{"date": parquet.Date()},
+		names: []string{"date"},
+	}
+	for _, s := range series {
+		if _, ok := group.Group[s.ID]; ok {
+			return fmt.Errorf("series ID %q clashes with another column", s.ID)
+		}
+		group.Group[s.ID] = parquet.Optional(parquet.Leaf(parquet.DoubleType))
+		group.names = append(group.names, s.ID)
+	}
+	schema := parquet.NewSchema("series", group)
+
+	writer := parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy))
+	for _, aligned := range bcch.Align(series...) {
+		row := make(parquet.Row, 0, len(group.names))
+		row = append(row, parquet.Int32Value(epochDays(aligned.Date)).Level(0, 0, 0))
+		for i, obs := range aligned.Observations {
+			column := i + 1
+			if obs == nil || obs.Missing {
+				row = append(row, parquet.NullValue().Level(0, 0, column))
+				continue
+			}
+			row = append(row, parquet.DoubleValue(obs.Value).Level(0, 1, column))
+		}
+		if _, err := writer.WriteRows([]parquet.Row{row}); err != nil {
+			return fmt.Errorf("error writing parquet row: %w", err)
+		}
+	}
+	return writer.Close()
+}
+
+// orderedGroup is a parquet.Group whose fields keep the order of names
+// instead of being sorted by name, so that the date column comes first and
+// the series follow in the order they were requested.
+type orderedGroup struct {
+	parquet.Group
+	names []string
+}
+
+func (g orderedGroup) Fields() []parquet.Field {
+	byName := make(map[string]parquet.Field, len(g.names))
+	for _, field := range g.Group.Fields() {
+		byName[field.Name()] = field
+	}
+	fields := make([]parquet.Field, len(g.names))
+	for i, name := range g.names {
+		fields[i] = byName[name]
+	}
+	return fields
+}
+
+// epochDays returns the days between the Unix epoch and the date of t.
+func epochDays(t time.Time) int32 {
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return int32(date.Unix() / 86400) // #nosec G115 -- dates within a few centuries of the epoch
+}
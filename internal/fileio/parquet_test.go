@@ -0,0 +1,101 @@
+package fileio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/parquet-go/parquet-go"
+)
+
+func testSeries() []bcch.Series {
+	day := func(d int) time.Time { return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC) }
+	return []bcch.Series{
+		{ID: "F073.TCO.PRE.Z.D", Observations: []bcch.Observation{
+			{Date: day(2), Value: 877.12, Status: bcch.StatusOK},
+			{Date: day(3), Missing: true, Status: bcch.StatusNoData},
+		}},
+		{ID: "F049.DES.TAS.INE.10.M", Observations: []bcch.Observation{
+			{Date: day(1), Value: 8.4, Status: bcch.StatusOK},
+		}},
+	}
+}
+
+func TestSaveSeriesToParquetLong(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "series.parquet")
+	if err := SaveSeriesToParquet(testSeries(), LongLayout, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows, err := parquet.ReadFile[longRow](path)
+	if err != nil {
+		t.Fatalf("error reading parquet file: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %v", len(rows))
+	}
+	first := rows[0]
+	if first.SeriesID != "F073.TCO.PRE.Z.D" || first.Date != epochDays(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) ||
+		first.Value == nil || *first.Value != 877.12 || first.Status != "OK" {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+	if rows[1].Value != nil || rows[1].Status != "ND" {
+		t.Errorf("expected missing value to be null, got %+v", rows[1])
+	}
+}
+
+func TestWriteSeriesParquetWide(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSeriesParquet(&buf, testSeries(), WideLayout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error opening parquet file: %v", err)
+	}
+	if n := f.NumRows(); n != 3 {
+		t.Fatalf("expected 3 rows, got %v", n)
+	}
+	// date first, then the series in the given order
+	columns := make(map[string]int)
+	for i, path := range f.Schema().Columns() {
+		columns[path[0]] = i
+	}
+	if columns["date"] != 0 || columns["F073.TCO.PRE.Z.D"] != 1 || columns["F049.DES.TAS.INE.10.M"] != 2 {
+		t.Errorf("expected columns date, F073.TCO.PRE.Z.D, F049.DES.TAS.INE.10.M, got %v", f.Schema().Columns())
+	}
+	date, ok := f.Schema().Lookup("date")
+	if !ok || date.Node.Type().LogicalType().Date == nil {
+		t.Errorf("expected date column with DATE logical type")
+	}
+
+	reader := parquet.NewReader(f)
+	rows := make([]parquet.Row, 3)
+	n, err := reader.ReadRows(rows)
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("error reading rows: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 rows read, got %v", n)
+	}
+
+	// rows are sorted by date: Jan 1 (monthly only), Jan 2, Jan 3 (missing)
+	daily, monthly := columns["F073.TCO.PRE.Z.D"], columns["F049.DES.TAS.INE.10.M"]
+	if !rows[0][daily].IsNull() || rows[0][monthly].Double() != 8.4 {
+		t.Errorf("unexpected first row: %v", rows[0])
+	}
+	if rows[1][daily].Double() != 877.12 || !rows[1][monthly].IsNull() {
+		t.Errorf("unexpected second row: %v", rows[1])
+	}
+	if !rows[2][daily].IsNull() {
+		t.Errorf("expected missing value to be null, got %v", rows[2])
+	}
+	if got, want := rows[0][columns["date"]].Int32(), epochDays(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); got != want {
+		t.Errorf("expected date %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,183 @@
+package fileio
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver
+)
+
+// sqliteSchema creates the tables synced by SyncSeriesSQLite. Dates are
+// YYYY-MM-DD text, so that they sort and work with the SQLite date
+// functions, and timestamps RFC 3339 text in UTC.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS series (
+	series_id         TEXT PRIMARY KEY,
+	frequency         TEXT NOT NULL,
+	spanish_title     TEXT NOT NULL,
+	english_title     TEXT NOT NULL,
+	first_observation TEXT,
+	last_observation  TEXT,
+	updated_at        TEXT,
+	created_at        TEXT,
+	synced_at         TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS observations (
+	series_id TEXT NOT NULL REFERENCES series (series_id),
+	date      TEXT NOT NULL,
+	value     REAL,
+	status    TEXT NOT NULL,
+	PRIMARY KEY (series_id, date)
+) WITHOUT ROWID;
+`
+
+const upsertSeriesSQL = `
+INSERT INTO series (series_id, frequency, spanish_title, english_title, first_observation, last_observation, updated_at, created_at, synced_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (series_id) DO UPDATE SET
+	frequency = excluded.frequency,
+	spanish_title = excluded.spanish_title,
+	english_title = excluded.english_title,
+	first_observation = excluded.first_observation,
+	last_observation = excluded.last_observation,
+	updated_at = excluded.updated_at,
+	created_at = excluded.created_at,
+	synced_at = excluded.synced_at
+`
+
+const upsertObservationSQL = `
+INSERT INTO observations (series_id, date, value, status)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (series_id, date) DO UPDATE SET
+	value = excluded.value,
+	status = excluded.status
+`
+
+const sqliteDateLayout = "2006-01-02"
+
+// SaveSeriesToSQLite syncs series into the SQLite database at filename,
+// creating it if it does not exist. See SyncSeriesSQLite.
+func SaveSeriesToSQLite(series []bcch.Series, catalog []bcch.SeriesInfo, syncedAt time.Time, filename string) error {
+	cleanPath := filepath.Clean(filename)
+	if err := os.MkdirAll(filepath.Dir(cleanPath), 0750); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", cleanPath)
+	if err != nil {
+		return err
+	}
+	if err := SyncSeriesSQLite(db, series, catalog, syncedAt); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// SyncSeriesSQLite creates the series and observations tables in db if
+// needed and upserts series into them in a single transaction, so that
+// running it again refreshes the metadata and revised values while keeping
+// the observations outside the fetched range.
+//
+// The series table takes its metadata from the catalog entry of every
+// series, falling back to the titles and frequency of the series and the
+// dates of its observations for series missing from catalog. The
+// observations table is keyed by series_id and date; values BCCh reports as
+// missing are NULL.
+func SyncSeriesSQLite(db *sql.DB, series []bcch.Series, catalog []bcch.SeriesInfo, syncedAt time.Time) error {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("error creating sqlite tables: %w", err)
+	}
+
+	infos := make(map[string]bcch.SeriesInfo, len(catalog))
+	for _, info := range catalog {
+		infos[info.SeriesID] = info
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	upsertSeries, err := tx.Prepare(upsertSeriesSQL)
+	if err != nil {
+		return err
+	}
+	defer upsertSeries.Close()
+	upsertObservation, err := tx.Prepare(upsertObservationSQL)
+	if err != nil {
+		return err
+	}
+	defer upsertObservation.Close()
+
+	syncedAtText := syncedAt.UTC().Format(time.RFC3339)
+	for _, s := range series {
+		info := seriesInfo(s, infos)
+		if _, err := upsertSeries.Exec(
+			s.ID,
+			info.FrequencyCode,
+			info.SpanishTitle,
+			info.EnglishTitle,
+			sqliteDate(info.FirstObservation),
+			sqliteDate(info.LastObservation),
+			sqliteDate(info.UpdatedAt),
+			sqliteDate(info.CreatedAt),
+			syncedAtText,
+		); err != nil {
+			return fmt.Errorf("error upserting series %s: %w", s.ID, err)
+		}
+
+		for _, obs := range s.Observations {
+			var value any
+			if !obs.Missing {
+				value = obs.Value
+			}
+			if _, err := upsertObservation.Exec(s.ID, obs.Date.Format(sqliteDateLayout), value, obs.Status.String()); err != nil {
+				return fmt.Errorf("error upserting observations of %s: %w", s.ID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// seriesInfo returns the catalog entry of s, filling the fields the catalog
+// lacks from s itself.
+func seriesInfo(s bcch.Series, infos map[string]bcch.SeriesInfo) bcch.SeriesInfo {
+	info := infos[s.ID]
+	if info.FrequencyCode == "" {
+		info.FrequencyCode = string(s.Frequency)
+	}
+	if info.SpanishTitle == "" {
+		info.SpanishTitle = s.SpanishTitle
+	}
+	if info.EnglishTitle == "" {
+		info.EnglishTitle = s.EnglishTitle
+	}
+	if n := len(s.Observations); n > 0 {
+		if info.FirstObservation == "" {
+			info.FirstObservation = s.Observations[0].Date.Format(bcch.ObservationDateLayout)
+		}
+		if info.LastObservation == "" {
+			info.LastObservation = s.Observations[n-1].Date.Format(bcch.ObservationDateLayout)
+		}
+	}
+	return info
+}
+
+// sqliteDate converts a date in the layout of BCCh responses to YYYY-MM-DD,
+// returning nil for an empty date and the date untouched if it cannot be
+// parsed.
+func sqliteDate(date string) any {
+	if date == "" {
+		return nil
+	}
+	t, err := time.Parse(bcch.ObservationDateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format(sqliteDateLayout)
+}
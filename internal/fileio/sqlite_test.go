@@ -0,0 +1,91 @@
+package fileio
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+)
+
+func TestSaveSeriesToSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db", "bcch.sqlite")
+	catalog := []bcch.SeriesInfo{{
+		SeriesID:         "F073.TCO.PRE.Z.D",
+		FrequencyCode:    "DAILY",
+		SpanishTitle:     "Tipo de cambio nominal (dólar observado $CLP/USD)",
+		EnglishTitle:     "Nominal exchange rate (observed dollar CLP/USD)",
+		FirstObservation: "03-01-1984",
+		LastObservation:  "05-01-2024",
+		UpdatedAt:        "05-01-2024",
+		CreatedAt:        "01-01-2010",
+	}}
+	firstSync := time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)
+	if err := SaveSeriesToSQLite(testSeries(), catalog, firstSync, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a second run over a later range revises a value and adds an
+	// observation, keeping the earlier ones
+	series := testSeries()
+	series[0].Observations = []bcch.Observation{
+		{Date: time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC), Value: 880.5, Status: bcch.StatusOK},
+	}
+	series[1].Observations = append(series[1].Observations, bcch.Observation{
+		Date: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Value: 8.5, Status: bcch.StatusOK,
+	})
+	secondSync := firstSync.Add(24 * time.Hour)
+	if err := SaveSeriesToSQLite(series, catalog, secondSync, path); err != nil {
+		t.Fatalf("unexpected error syncing again: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM observations`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 observations, got %v", count)
+	}
+
+	var value sql.NullFloat64
+	var status string
+	err = db.QueryRow(`SELECT value, status FROM observations WHERE series_id = ? AND date = ?`, "F073.TCO.PRE.Z.D", "2024-01-03").Scan(&value, &status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !value.Valid || value.Float64 != 880.5 || status != "OK" {
+		t.Errorf("expected revised value 880.5, got %v %v", value, status)
+	}
+	err = db.QueryRow(`SELECT value FROM observations WHERE series_id = ? AND date = ?`, "F073.TCO.PRE.Z.D", "2024-01-02").Scan(&value)
+	if err != nil || !value.Valid || value.Float64 != 877.12 {
+		t.Errorf("expected observation outside the second run to be kept, got %v (%v)", value, err)
+	}
+
+	var frequency, title, first, syncedAt string
+	err = db.QueryRow(`SELECT frequency, english_title, first_observation, synced_at FROM series WHERE series_id = ?`, "F073.TCO.PRE.Z.D").
+		Scan(&frequency, &title, &first, &syncedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frequency != "DAILY" || title != catalog[0].EnglishTitle || first != "1984-01-03" || syncedAt != "2024-01-06T12:00:00Z" {
+		t.Errorf("expected catalog metadata, got %v %q %v %v", frequency, title, first, syncedAt)
+	}
+
+	// F049.DES.TAS.INE.10.M is not in the catalog
+	var last string
+	err = db.QueryRow(`SELECT frequency, first_observation, last_observation FROM series WHERE series_id = ?`, "F049.DES.TAS.INE.10.M").
+		Scan(&frequency, &first, &last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "2024-01-01" || last != "2024-02-01" {
+		t.Errorf("expected observation range as fallback, got %v to %v", first, last)
+	}
+}
@@ -0,0 +1,253 @@
+package fileio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of the workbook written by WriteSeriesXLSX, besides the one per
+// series.
+const (
+	CombinedSheet = "Combined"
+	MetadataSheet = "Metadata"
+)
+
+const (
+	xlsxDateFormat     = "yyyy-mm-dd"
+	xlsxDateTimeFormat = "yyyy-mm-dd hh:mm:ss"
+	// maxSheetName is the longest sheet name Excel accepts.
+	maxSheetName = 31
+)
+
+// SaveSeriesToXLSX writes series to filename as an Excel workbook. See
+// WriteSeriesXLSX.
+func SaveSeriesToXLSX(series []bcch.Series, catalog []bcch.SeriesInfo, fetchedAt time.Time, filename string) error {
+	file, err := Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := WriteSeriesXLSX(file, series, catalog, fetchedAt); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteSeriesXLSX writes series to w as an Excel workbook with a Combined
+// sheet, aligning the series on their dates, one sheet per series with its
+// date, value and status, and a Metadata sheet describing the series and
+// when they were fetched. Dates are typed as dates and values as numbers;
+// cells without value are left empty.
+//
+// The Metadata sheet takes the titles, frequency and first and last
+// observations of every series from its catalog entry, falling back to the
+// series itself and the dates fetched for series missing from catalog.
+func WriteSeriesXLSX(w io.Writer, series []bcch.Series, catalog []bcch.SeriesInfo, fetchedAt time.Time) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	wb := xlsxWorkbook{file: f}
+	if err := wb.init(); err != nil {
+		return err
+	}
+	if err := f.SetSheetName("Sheet1", CombinedSheet); err != nil {
+		return err
+	}
+	if err := wb.writeCombined(series); err != nil {
+		return err
+	}
+
+	used := map[string]bool{strings.ToLower(CombinedSheet): true, strings.ToLower(MetadataSheet): true}
+	sheets := make([]string, len(series))
+	for i, s := range series {
+		sheets[i] = sheetName(s.ID, used)
+		if _, err := f.NewSheet(sheets[i]); err != nil {
+			return err
+		}
+		if err := wb.writeSeries(sheets[i], s); err != nil {
+			return err
+		}
+	}
+
+	if _, err := f.NewSheet(MetadataSheet); err != nil {
+		return err
+	}
+	if err := wb.writeMetadata(series, catalog, sheets, fetchedAt); err != nil {
+		return err
+	}
+
+	f.SetActiveSheet(0)
+	return f.Write(w)
+}
+
+// xlsxWorkbook writes the sheets of a workbook with shared cell styles.
+type xlsxWorkbook struct {
+	file          *excelize.File
+	headerStyle   int
+	dateStyle     int
+	dateTimeStyle int
+}
+
+func (wb *xlsxWorkbook) init() error {
+	var err error
+	if wb.headerStyle, err = wb.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return err
+	}
+	dateFormat := xlsxDateFormat
+	if wb.dateStyle, err = wb.file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return err
+	}
+	dateTimeFormat := xlsxDateTimeFormat
+	if wb.dateTimeStyle, err = wb.file.NewStyle(&excelize.Style{CustomNumFmt: &dateTimeFormat}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wb *xlsxWorkbook) writeCombined(series []bcch.Series) error {
+	header := []any{"Date"}
+	for _, s := range series {
+		header = append(header, s.ID)
+	}
+	rows := [][]any{}
+	for _, aligned := range bcch.Align(series...) {
+		row := []any{aligned.Date}
+		for _, obs := range aligned.Observations {
+			row = append(row, cellValue(obs))
+		}
+		rows = append(rows, row)
+	}
+	return wb.writeSheet(CombinedSheet, header, rows, 14, map[string]int{"A": wb.dateStyle})
+}
+
+func (wb *xlsxWorkbook) writeSeries(sheet string, s bcch.Series) error {
+	rows := make([][]any, 0, len(s.Observations))
+	for i := range s.Observations {
+		obs := &s.Observations[i]
+		rows = append(rows, []any{obs.Date, cellValue(obs), obs.Status.String()})
+	}
+	return wb.writeSheet(sheet, []any{"Date", "Value", "Status"}, rows, 14, map[string]int{"A": wb.dateStyle})
+}
+
+func (wb *xlsxWorkbook) writeMetadata(series []bcch.Series, catalog []bcch.SeriesInfo, sheets []string, fetchedAt time.Time) error {
+	infos := make(map[string]bcch.SeriesInfo, len(catalog))
+	for _, info := range catalog {
+		infos[info.SeriesID] = info
+	}
+
+	header := []any{"Series ID", "Sheet", "Spanish title", "English title", "Frequency", "First observation", "Last observation", "Observations", "Fetched at (UTC)"}
+	rows := make([][]any, 0, len(series))
+	for i, s := range series {
+		info := seriesInfo(s, infos)
+		rows = append(rows, []any{
+			s.ID,
+			sheets[i],
+			info.SpanishTitle,
+			info.EnglishTitle,
+			info.FrequencyCode,
+			xlsxDate(info.FirstObservation),
+			xlsxDate(info.LastObservation),
+			len(s.Observations),
+			fetchedAt.UTC(),
+		})
+	}
+	styles := map[string]int{"F:G": wb.dateStyle, "I": wb.dateTimeStyle}
+	if err := wb.writeSheet(MetadataSheet, header, rows, 20, styles); err != nil {
+		return err
+	}
+	return wb.file.SetColWidth(MetadataSheet, "C", "D", 50)
+}
+
+// writeSheet writes a bold, frozen header and rows to sheet, setting the
+// width of its columns and the style of the given column ranges.
+func (wb *xlsxWorkbook) writeSheet(sheet string, header []any, rows [][]any, width float64, styles map[string]int) error {
+	f := wb.file
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	lastCol, err := excelize.ColumnNumberToName(len(header))
+	if err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "A", lastCol, width); err != nil {
+		return err
+	}
+	for cols, style := range styles {
+		if err := f.SetColStyle(sheet, cols, style); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(sheet, "A1", lastCol+"1", wb.headerStyle); err != nil {
+		return err
+	}
+	return f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
+
+// xlsxDate converts a date in the layout of BCCh responses to a date cell,
+// returning nil for an empty date and the date untouched if it cannot be
+// parsed.
+func xlsxDate(date string) any {
+	if date == "" {
+		return nil
+	}
+	t, err := time.Parse(bcch.ObservationDateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t
+}
+
+// cellValue returns the value of obs, nil for a gap or a missing value so
+// that the cell is left empty.
+func cellValue(obs *bcch.Observation) any {
+	if obs == nil || obs.Missing {
+		return nil
+	}
+	return obs.Value
+}
+
+// sheetName returns a sheet name for the series ID that Excel accepts and
+// is not yet in used, and marks it as used. Excel compares sheet names
+// ignoring case, so used holds lowercase names.
+func sheetName(seriesID string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, seriesID)
+	if len(name) > maxSheetName {
+		name = name[:maxSheetName]
+	}
+	if name == "" {
+		name = "Series"
+	}
+
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		suffix := fmt.Sprintf("~%d", i)
+		candidate = name[:min(len(name), maxSheetName-len(suffix))] + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
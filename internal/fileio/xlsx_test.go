@@ -0,0 +1,138 @@
+package fileio
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSaveSeriesToXLSX(t *testing.T) {
+	series := testSeries()
+	series[0].SpanishTitle = "Tipo de cambio nominal (dólar observado $CLP/USD)"
+	fetchedAt := time.Date(2024, time.February, 1, 12, 30, 0, 0, time.UTC)
+	catalog := []bcch.SeriesInfo{{
+		SeriesID:         "F073.TCO.PRE.Z.D",
+		FrequencyCode:    "DAILY",
+		EnglishTitle:     "Nominal exchange rate (observed dollar $CLP/USD)",
+		FirstObservation: "02-01-1984",
+		LastObservation:  "15-03-2024",
+	}}
+
+	path := filepath.Join(t.TempDir(), "series.xlsx")
+	if err := SaveSeriesToXLSX(series, catalog, fetchedAt, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("error opening workbook: %v", err)
+	}
+	defer f.Close()
+
+	expectedSheets := []string{CombinedSheet, "F073.TCO.PRE.Z.D", "F049.DES.TAS.INE.10.M", MetadataSheet}
+	if sheets := f.GetSheetList(); !slices.Equal(sheets, expectedSheets) {
+		t.Fatalf("expected sheets %v, got %v", expectedSheets, sheets)
+	}
+
+	combined, err := f.GetRows(CombinedSheet)
+	if err != nil {
+		t.Fatalf("error reading combined sheet: %v", err)
+	}
+	expectedCombined := [][]string{
+		{"Date", "F073.TCO.PRE.Z.D", "F049.DES.TAS.INE.10.M"},
+		{"2024-01-01", "", "8.4"},
+		{"2024-01-02", "877.12"},
+		{"2024-01-03"},
+	}
+	if !slices.EqualFunc(combined, expectedCombined, slices.Equal) {
+		t.Errorf("expected combined rows %q, got %q", expectedCombined, combined)
+	}
+
+	// dates are serial numbers with a date format, values plain numbers
+	const sheet = "F073.TCO.PRE.Z.D"
+	if raw, _ := f.GetCellValue(sheet, "A2", excelize.Options{RawCellValue: true}); raw != "45293" {
+		t.Errorf("expected 2024-01-02 as the date serial 45293, got %q", raw)
+	}
+	styleID, err := f.GetCellStyle(sheet, "A2")
+	if err != nil {
+		t.Fatalf("error reading cell style: %v", err)
+	}
+	if style, err := f.GetStyle(styleID); err != nil || style.CustomNumFmt == nil || *style.CustomNumFmt != xlsxDateFormat {
+		t.Errorf("expected dates formatted as %s, got %+v (%v)", xlsxDateFormat, style, err)
+	}
+	if cellType, _ := f.GetCellType(sheet, "C2"); cellType != excelize.CellTypeSharedString {
+		t.Errorf("expected status to be a string, got %v", cellType)
+	}
+	if raw, _ := f.GetCellValue(sheet, "B2", excelize.Options{RawCellValue: true}); raw != "877.12" {
+		t.Errorf("expected value 877.12, got %q", raw)
+	}
+	if status, _ := f.GetCellValue(sheet, "C3"); status != "ND" {
+		t.Errorf("expected status ND, got %q", status)
+	}
+	if value, _ := f.GetCellValue(sheet, "B3"); value != "" {
+		t.Errorf("expected missing value to be empty, got %q", value)
+	}
+
+	metadata, err := f.GetRows(MetadataSheet)
+	if err != nil {
+		t.Fatalf("error reading metadata sheet: %v", err)
+	}
+	if len(metadata) != 3 {
+		t.Fatalf("expected 3 metadata rows, got %v", len(metadata))
+	}
+	// the catalog describes the whole series, the series missing from it
+	// fall back to the fetched dates
+	expectedMetadata := [][]string{
+		{"F073.TCO.PRE.Z.D", "F073.TCO.PRE.Z.D", series[0].SpanishTitle, catalog[0].EnglishTitle, "DAILY", "1984-01-02", "2024-03-15", "2", "2024-02-01 12:30:00"},
+		{"F049.DES.TAS.INE.10.M", "F049.DES.TAS.INE.10.M", "", "", "", "2024-01-01", "2024-01-01", "1", "2024-02-01 12:30:00"},
+	}
+	if !slices.EqualFunc(metadata[1:], expectedMetadata, slices.Equal) {
+		t.Errorf("expected metadata rows %q, got %q", expectedMetadata, metadata[1:])
+	}
+}
+
+func TestWriteSeriesXLSXReservedSheetNames(t *testing.T) {
+	series := testSeries()
+	series[0].ID, series[1].ID = "COMBINED", "metadata"
+
+	var buf bytes.Buffer
+	if err := WriteSeriesXLSX(&buf, series, nil, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("error opening workbook: %v", err)
+	}
+	defer f.Close()
+
+	expectedSheets := []string{CombinedSheet, "COMBINED~2", "metadata~2", MetadataSheet}
+	if sheets := f.GetSheetList(); !slices.Equal(sheets, expectedSheets) {
+		t.Errorf("expected sheets %v, got %v", expectedSheets, sheets)
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	used := map[string]bool{"combined": true}
+	tests := []struct {
+		seriesID string
+		expected string
+	}{
+		{"F073.TCO.PRE.Z.D", "F073.TCO.PRE.Z.D"},
+		{"Combined", "Combined~2"},
+		{"COMBINED", "COMBINED~3"},
+		{"A/B:C", "A_B_C"},
+		{strings.Repeat("X", 40), strings.Repeat("X", 31)},
+		{strings.Repeat("X", 40), strings.Repeat("X", 29) + "~2"},
+	}
+	for _, tc := range tests {
+		if got := sheetName(tc.seriesID, used); got != tc.expected {
+			t.Errorf("sheetName(%q): expected %q, got %q", tc.seriesID, tc.expected, got)
+		}
+	}
+}
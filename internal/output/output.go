@@ -0,0 +1,215 @@
+// Package output renders tabular command results as human-readable tables
+// or machine-friendly formats.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/internal/fileio"
+)
+
+// Format is an output format selected with --output.
+type Format string
+
+const (
+	Table    Format = "table"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	Markdown Format = "markdown"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Table, CSV, TSV, JSON, NDJSON, Markdown}
+
+// ParseFormat parses the name of a format, case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, format := range Formats {
+		if f == format {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format %q, must be one of: %s", s, formatNames())
+}
+
+func formatNames() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Data is a table of results. Cells hold a string, a float64 or nil for a
+// gap, like a date without observation of a series. A NaN float64 is a
+// missing value.
+type Data struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Write renders data to w in the given format.
+//
+// Numbers are written with as many digits as needed and no thousands
+// separator. Gaps are written as '-' in tables, as empty fields in CSV and
+// TSV, and as null in JSON, where missing values are null as well.
+func Write(w io.Writer, format Format, data Data) error {
+	switch format {
+	case Table:
+		return writeTable(w, data)
+	case CSV:
+		return writeDelimited(w, ',', data)
+	case TSV:
+		return writeDelimited(w, '\t', data)
+	case JSON:
+		return fileio.WriteJSON(w, objects(data))
+	case NDJSON:
+		return writeNDJSON(w, data)
+	case Markdown:
+		return writeMarkdown(w, data)
+	}
+	return fmt.Errorf("invalid output format %q", format)
+}
+
+func writeTable(w io.Writer, data Data) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(data.Columns, "\t"))
+	for _, row := range data.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = formatCell(v, "-")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeDelimited(w io.Writer, comma rune, data Data) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(data.Columns); err != nil {
+		return err
+	}
+	for _, row := range data.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = formatCell(v, "")
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeNDJSON(w io.Writer, data Data) error {
+	encoder := json.NewEncoder(w)
+	for _, obj := range objects(data) {
+		if err := encoder.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdown(w io.Writer, data Data) error {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	writeRow := func(cells []string) error {
+		for i, cell := range cells {
+			cells[i] = escape.Replace(cell)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	}
+
+	if err := writeRow(append([]string(nil), data.Columns...)); err != nil {
+		return err
+	}
+	separator := make([]string, len(data.Columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if err := writeRow(separator); err != nil {
+		return err
+	}
+	for _, row := range data.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = formatCell(v, "-")
+		}
+		if err := writeRow(cells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatCell formats v for text formats, writing gap for nil.
+func formatCell(v any, gap string) string {
+	switch v := v.(type) {
+	case nil:
+		return gap
+	case string:
+		return v
+	case float64:
+		if math.IsNaN(v) {
+			return "NaN"
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// object is a row encoded as a JSON object keeping the column order.
+type object struct {
+	columns []string
+	row     []any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, column := range o.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if i < len(o.row) {
+			v = o.row[i]
+		}
+		if f, ok := v.(float64); ok && math.IsNaN(f) {
+			v = nil
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+func objects(data Data) []object {
+	objs := make([]object, len(data.Rows))
+	for i, row := range data.Rows {
+		objs[i] = object{columns: data.Columns, row: row}
+	}
+	return objs
+}
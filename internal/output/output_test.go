@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	data := Data{
+		Columns: []string{"date", "A|B", "C"},
+		Rows: [][]any{
+			{"2024-01-01", 1.5, nil},
+			{"2024-01-02", math.NaN(), 1000000.0},
+		},
+	}
+
+	tests := map[Format]string{
+		Table: "date        A|B  C\n" +
+			"2024-01-01  1.5  -\n" +
+			"2024-01-02  NaN  1000000\n",
+		CSV: "date,A|B,C\n" +
+			"2024-01-01,1.5,\n" +
+			"2024-01-02,NaN,1000000\n",
+		TSV: "date\tA|B\tC\n" +
+			"2024-01-01\t1.5\t\n" +
+			"2024-01-02\tNaN\t1000000\n",
+		NDJSON: `{"date":"2024-01-01","A|B":1.5,"C":null}` + "\n" +
+			`{"date":"2024-01-02","A|B":null,"C":1000000}` + "\n",
+		JSON: "[\n" +
+			"  {\n    \"date\": \"2024-01-01\",\n    \"A|B\": 1.5,\n    \"C\": null\n  },\n" +
+			"  {\n    \"date\": \"2024-01-02\",\n    \"A|B\": null,\n    \"C\": 1000000\n  }\n" +
+			"]\n",
+		Markdown: "| date | A\\|B | C |\n" +
+			"| --- | --- | --- |\n" +
+			"| 2024-01-01 | 1.5 | - |\n" +
+			"| 2024-01-02 | NaN | 1000000 |\n",
+	}
+	for format, want := range tests {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := buf.String(); got != want {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(" CSV "); err != nil || f != CSV {
+		t.Errorf("expected csv, got %v (%v)", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
@@ -0,0 +1,45 @@
+package bcch
+
+import (
+	"slices"
+	"time"
+)
+
+// AlignedRow holds the observations of several series on one date.
+type AlignedRow struct {
+	Date time.Time
+	// Observations has one entry per series, in the order given to Align,
+	// nil where the series has no observation on Date.
+	Observations []*Observation
+}
+
+// Align joins series on their observation dates, for wide tables with one
+// column per series. The rows are sorted by date and cover every date
+// observed in any of the series, so series of different frequencies or
+// ranges leave nil gaps.
+func Align(series ...Series) []AlignedRow {
+	rowByDate := make(map[time.Time]*AlignedRow)
+	for i, s := range series {
+		for j := range s.Observations {
+			obs := &s.Observations[j]
+			row, ok := rowByDate[obs.Date]
+			if !ok {
+				row = &AlignedRow{
+					Date:         obs.Date,
+					Observations: make([]*Observation, len(series)),
+				}
+				rowByDate[obs.Date] = row
+			}
+			row.Observations[i] = obs
+		}
+	}
+
+	rows := make([]AlignedRow, 0, len(rowByDate))
+	for _, row := range rowByDate {
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b AlignedRow) int {
+		return a.Date.Compare(b.Date)
+	})
+	return rows
+}
@@ -0,0 +1,172 @@
+package bcch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	credentialsFile = "credentials.json" // #nosec G101
+	DefaultProfile  = "default"
+)
+
+// ErrNoCredentials is matched by the errors of Load when no credentials are
+// saved for the profile.
+var ErrNoCredentials = errors.New("no credentials saved")
+
+// Environment variables read by Load. EnvUser and EnvPassword take
+// precedence over the credentials file, which EnvCredentialsFile relocates.
+const (
+	EnvUser            = "BCCH_USER"
+	EnvPassword        = "BCCH_PASSWORD" // #nosec G101
+	EnvCredentialsFile = "BCCH_CREDENTIALS_FILE"
+)
+
+// AuthConfig holds the credentials of the BCCh web service.
+type AuthConfig struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// Profile selects the named set of credentials to load and save,
+	// DefaultProfile when empty.
+	Profile string `json:"-"`
+	// Path is the credentials file. When empty, EnvCredentialsFile or else
+	// DefaultCredentialsPath is used.
+	Path string `json:"-"`
+	// Store reads and writes the credentials file, DefaultCredentialStore
+	// when nil.
+	Store CredentialStore `json:"-"`
+}
+
+// credentialsStore is the content of the credentials file.
+type credentialsStore struct {
+	Profiles map[string]AuthConfig `json:"profiles"`
+}
+
+// DefaultCredentialsPath returns the credentials file inside the user
+// config directory.
+func DefaultCredentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bcch", credentialsFile), nil
+}
+
+func (a *AuthConfig) profile() string {
+	if a.Profile == "" {
+		return DefaultProfile
+	}
+	return a.Profile
+}
+
+func (a *AuthConfig) store() CredentialStore {
+	if a.Store == nil {
+		return DefaultCredentialStore()
+	}
+	return a.Store
+}
+
+// FilePath returns the credentials file read by Load and written by Save.
+func (a *AuthConfig) FilePath() (string, error) {
+	if a.Path != "" {
+		return filepath.Clean(a.Path), nil
+	}
+	if path := os.Getenv(EnvCredentialsFile); path != "" {
+		return filepath.Clean(path), nil
+	}
+	return DefaultCredentialsPath()
+}
+
+// Loads credentials into the given AuthConfig struct. EnvUser and
+// EnvPassword override the stored profile; when both are set the
+// credentials file is not read at all.
+func (a *AuthConfig) Load() error {
+	envUser, envPassword := os.Getenv(EnvUser), os.Getenv(EnvPassword)
+	if envUser != "" && envPassword != "" {
+		a.User = envUser
+		a.Password = envPassword
+		return nil
+	}
+
+	path, err := a.FilePath()
+	if err != nil {
+		return err
+	}
+
+	store, err := readCredentialsStore(path, a.store())
+	if err != nil {
+		return err
+	}
+	creds, ok := store.Profiles[a.profile()]
+	if !ok {
+		return fmt.Errorf("%w for profile %q", ErrNoCredentials, a.profile())
+	}
+	a.User = creds.User
+	a.Password = creds.Password
+	if envUser != "" {
+		a.User = envUser
+	}
+	if envPassword != "" {
+		a.Password = envPassword
+	}
+	return nil
+}
+
+// Saves authconfig back to disk
+func (a *AuthConfig) Save() error {
+	path, err := a.FilePath()
+	if err != nil {
+		return err
+	}
+
+	cs := a.store()
+	store, err := readCredentialsStore(path, cs)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	store.Profiles[a.profile()] = AuthConfig{
+		User:     a.User,
+		Password: a.Password,
+	}
+	return writeCredentialsStore(path, store, cs)
+}
+
+// readCredentialsStore reads the credentials file through cs. A missing file
+// returns an empty store along with an error matching ErrNoCredentials and
+// os.ErrNotExist.
+func readCredentialsStore(path string, cs CredentialStore) (credentialsStore, error) {
+	store := credentialsStore{Profiles: make(map[string]AuthConfig)}
+	dat, err := cs.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, fmt.Errorf("%w: %w", ErrNoCredentials, err)
+	}
+	if errors.Is(err, ErrCredentialsEncrypted) {
+		return store, err
+	}
+	if err != nil {
+		return store, fmt.Errorf("error reading credentials: %w", err)
+	}
+	if err := json.Unmarshal(dat, &store); err != nil {
+		return store, fmt.Errorf("error during unmarshal of credentials (JSON): %w", err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]AuthConfig)
+	}
+	return store, nil
+}
+
+// writeCredentialsStore writes the credentials file through cs, readable
+// only by the current user and replaced atomically.
+func writeCredentialsStore(path string, store credentialsStore, cs CredentialStore) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := cs.WriteFile(path, data); err != nil {
+		return fmt.Errorf("error saving credentials: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package bcch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAuthConfigProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bcch", credentialsFile)
+
+	for _, a := range []AuthConfig{
+		{User: "default-user", Password: "default-pass", Path: path},
+		{User: "work-user", Password: "work-pass", Profile: "work", Path: path},
+	} {
+		if err := a.Save(); err != nil {
+			t.Fatalf("unexpected error saving profile %q: %v", a.profile(), err)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected error reading credentials file: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("expected credentials file permissions 0600, got %v", perm)
+		}
+	}
+
+	work := AuthConfig{Profile: "work", Path: path}
+	if err := work.Load(); err != nil {
+		t.Fatalf("unexpected error loading profile work: %v", err)
+	}
+	if work.User != "work-user" || work.Password != "work-pass" {
+		t.Errorf("unexpected credentials for profile work: %+v", work)
+	}
+
+	def := AuthConfig{Path: path}
+	if err := def.Load(); err != nil {
+		t.Fatalf("unexpected error loading default profile: %v", err)
+	}
+	if def.User != "default-user" {
+		t.Errorf("unexpected credentials for default profile: %+v", def)
+	}
+
+	missing := AuthConfig{Profile: "missing", Path: path}
+	if err := missing.Load(); err == nil {
+		t.Errorf("expected error loading missing profile")
+	}
+}
+
+func TestAuthConfigEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), credentialsFile)
+	saved := AuthConfig{User: "saved-user", Password: "saved-pass", Path: path}
+	if err := saved.Save(); err != nil {
+		t.Fatalf("unexpected error saving credentials: %v", err)
+	}
+
+	t.Setenv(EnvCredentialsFile, path)
+	t.Setenv(EnvPassword, "env-pass")
+
+	a := AuthConfig{}
+	if err := a.Load(); err != nil {
+		t.Fatalf("unexpected error loading credentials: %v", err)
+	}
+	if a.User != "saved-user" || a.Password != "env-pass" {
+		t.Errorf("expected saved user and env password, got %+v", a)
+	}
+
+	t.Setenv(EnvUser, "env-user")
+	t.Setenv(EnvCredentialsFile, filepath.Join(t.TempDir(), "missing.json"))
+
+	a = AuthConfig{}
+	if err := a.Load(); err != nil {
+		t.Fatalf("unexpected error loading credentials from env: %v", err)
+	}
+	if a.User != "env-user" || a.Password != "env-pass" {
+		t.Errorf("expected env credentials, got %+v", a)
+	}
+}
+
+func TestAuthConfigLoadIgnoresWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	// older CLI versions saved the credentials here, the SDK must not touch it
+	legacy := filepath.Join(dir, ".bcch_credentials")
+	if err := os.WriteFile(legacy, []byte(`{"user":"old-user","password":"old-pass"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := AuthConfig{Path: filepath.Join(t.TempDir(), credentialsFile)}
+	err = a.Load()
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+	if strings.Contains(err.Error(), "setCredentials") {
+		t.Errorf("expected no CLI commands in SDK errors, got %v", err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("expected the file in the working directory to be kept, got %v", err)
+	}
+	if _, err := os.Stat(a.Path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no credentials file to be written, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package bcch
+
+const (
+	// DefaultBaseURL is the BCCh web service used unless WithBaseURL is given.
+	DefaultBaseURL = "https://si3.bcentral.cl/SieteRestWS/"
+	// DefaultUserAgent is sent unless WithUserAgent is given.
+	DefaultUserAgent = "chile-economic-indexes-cli"
+)
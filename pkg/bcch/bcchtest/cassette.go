@@ -0,0 +1,273 @@
+package bcchtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EnvRecord switches cassettes to record mode when set to "1" or "true".
+// Recording sends the requests to the real BCCh web service, so the test
+// needs valid credentials.
+const EnvRecord = "BCCH_RECORD"
+
+// scrubbed replaces the credentials in recorded cassettes.
+const scrubbed = "SCRUBBED"
+
+// ErrNoInteraction is returned by RoundTrip in replay mode when the
+// cassette has no interaction for the request.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Mode tells a Recorder whether to record real exchanges or replay them.
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when EnvRecord is set, ModeReplay
+// otherwise.
+func ModeFromEnv() Mode {
+	switch strings.ToLower(os.Getenv(EnvRecord)) {
+	case "1", "true":
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request with the credentials removed from its URL.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is a response. JSON bodies are stored as is to keep the
+// cassette readable, other bodies as text.
+type RecordedResponse struct {
+	StatusCode int             `json:"status"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"bodyText,omitempty"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records exchanges with the BCCh web
+// service to a cassette file, or replays them from it, so tests run against
+// real payload shapes without network or credentials. Give it to
+// bcch.WithTransport.
+type Recorder struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	used     map[int]bool
+}
+
+// NewRecorder returns a recorder for the cassette at path. In replay mode
+// the cassette must exist; in record mode requests go through next,
+// http.DefaultTransport when nil, and Save writes the cassette.
+func NewRecorder(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		mode: mode,
+		path: path,
+		next: next,
+		used: make(map[int]bool),
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	dat, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette (record it with %s=1): %w", EnvRecord, err)
+	}
+	if err := json.Unmarshal(dat, &r.cassette); err != nil {
+		return nil, fmt.Errorf("error during unmarshal of cassette %s (JSON): %w", path, err)
+	}
+	return r, nil
+}
+
+// UseCassette returns a recorder for the cassette at path in the mode
+// selected by EnvRecord. In record mode the cassette is saved when the test
+// ends, unless it failed, so that a broken recording never replaces a good
+// one.
+func UseCassette(tb testing.TB, path string) *Recorder {
+	tb.Helper()
+	r, err := NewRecorder(path, ModeFromEnv(), nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if r.mode == ModeRecord {
+		tb.Cleanup(func() {
+			if tb.Failed() {
+				tb.Logf("cassette %s not saved, the test failed", path)
+				return
+			}
+			if err := r.Save(); err != nil {
+				tb.Errorf("error saving cassette: %v", err)
+			}
+		})
+	}
+	return r
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    scrubURL(req.URL),
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  recorded,
+		Response: recordResponse(resp, scrubBody(body, req.URL)),
+	})
+	return resp, nil
+}
+
+// replay answers with the first unused interaction matching the request,
+// or with the last matching one once all have been used.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request != recorded {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("cassette %s: %w for %s %s", r.path, ErrNoInteraction, recorded.Method, recorded.URL)
+	}
+	r.used[match] = true
+
+	recordedResp := r.cassette.Interactions[match].Response
+	body := []byte(recordedResp.BodyText)
+	if len(recordedResp.Body) > 0 {
+		body = recordedResp.Body
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recordedResp.StatusCode, http.StatusText(recordedResp.StatusCode)),
+		StatusCode:    recordedResp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recordedResp.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It is a
+// no-op in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0600)
+}
+
+func recordResponse(resp *http.Response, body []byte) RecordedResponse {
+	recorded := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     http.Header{},
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		recorded.Header.Set("Content-Type", ct)
+	}
+	if json.Valid(body) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, body); err == nil {
+			recorded.Body = compact.Bytes()
+			return recorded
+		}
+	}
+	recorded.BodyText = string(body)
+	return recorded
+}
+
+// scrubURL returns u with the credentials replaced and the query encoded
+// canonically, so recorded and replayed requests match regardless of the
+// credentials in use.
+func scrubURL(u *url.URL) string {
+	scrubbedURL := *u
+	params := u.Query()
+	for _, key := range []string{"user", "pass"} {
+		if params.Has(key) {
+			params.Set(key, scrubbed)
+		}
+	}
+	scrubbedURL.RawQuery = params.Encode()
+	return scrubbedURL.String()
+}
+
+// scrubBody removes the credentials of the request from a response body.
+func scrubBody(body []byte, u *url.URL) []byte {
+	params := u.Query()
+	for _, key := range []string{"user", "pass"} {
+		if secret := params.Get(key); len(secret) >= 4 {
+			body = bytes.ReplaceAll(body, []byte(secret), []byte(scrubbed))
+		}
+	}
+	return body
+}
@@ -0,0 +1,78 @@
+package bcchtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := srv.URL + "/SieteRestWS.ashx?user=" + User + "&pass=" + Password + "&function=GetSeries&timeseries=F073.TCO.PRE.Z.D"
+
+	recorder, err := NewRecorder(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := get(t, &http.Client{Transport: recorder}, url)
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(replayer.cassette.Interactions); n != 1 {
+		t.Fatalf("expected 1 interaction, got %v", n)
+	}
+	if u := replayer.cassette.Interactions[0].Request.URL; strings.Contains(u, User) || strings.Contains(u, Password) {
+		t.Errorf("expected credentials scrubbed from %v", u)
+	}
+
+	// replay matches whatever credentials the client uses
+	otherURL := strings.NewReplacer(User, "someone", Password, "secret").Replace(url)
+	srv.Close()
+	if replayed := get(t, &http.Client{Transport: replayer}, otherURL); replayed != recorded {
+		t.Errorf("expected replayed body %v, got %v", recorded, replayed)
+	}
+
+	_, err = replayer.RoundTrip(mustRequest(t, srv.URL+"/SieteRestWS.ashx?function=SearchSeries"))
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Do(mustRequest(t, url))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		t.Fatal(err)
+	}
+	return compact.String()
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,350 @@
+// Package bcchtest provides an in-process fake of the BCCh SieteRestWS web
+// service for tests of code built on bcch.
+//
+// The server answers SearchSeries and GetSeries from fixture files, applies
+// the firstdate/lastdate filters, reports errors through Codigo like the
+// real service and can inject latency and failures:
+//
+//	srv := bcchtest.NewServer()
+//	defer srv.Close()
+//	client := bcch.NewClient(time.Second, cache, bcch.WithBaseURL(srv.URL))
+//	client.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+package bcchtest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials accepted by a new Server.
+const (
+	User     = "bcchtest-user"
+	Password = "bcchtest-password" // #nosec G101
+)
+
+// Error codes and descriptions returned in Codigo/Descripcion.
+const (
+	CodeSuccess            = 0
+	CodeInvalidDates       = -1
+	CodeInvalidCredentials = -5
+	CodeUnknownSeries      = -50
+
+	descSuccess            = "Success"
+	descInvalidCredentials = "Invalid username or password"
+	descUnknownSeries      = "The series does not exist"
+	descInvalidDates       = "Fecha inicial mayor a fecha final"
+)
+
+const (
+	requestDateLayout     = "2006-01-02"
+	observationDateLayout = "02-01-2006"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Observation is an observation as returned by GetSeries.
+type Observation struct {
+	IndexDateString string `json:"indexDateString"`
+	Value           string `json:"value"`
+	StatusCode      string `json:"statusCode"`
+}
+
+// Series is a series served by GetSeries.
+type Series struct {
+	DescripEsp string        `json:"descripEsp"`
+	DescripIng string        `json:"descripIng"`
+	SeriesID   string        `json:"seriesId"`
+	Obs        []Observation `json:"Obs"`
+}
+
+// SeriesInfo is a catalog entry served by SearchSeries.
+type SeriesInfo struct {
+	SeriesID         string `json:"seriesId"`
+	FrequencyCode    string `json:"frequencyCode"`
+	SpanishTitle     string `json:"spanishTitle"`
+	EnglishTitle     string `json:"englishTitle"`
+	FirstObservation string `json:"firstObservation"`
+	LastObservation  string `json:"lastObservation"`
+	UpdatedAt        string `json:"updatedAt"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+type response struct {
+	Codigo      int          `json:"Codigo"`
+	Descripcion string       `json:"Descripcion"`
+	Series      *Series      `json:"Series"`
+	SeriesInfos []SeriesInfo `json:"SeriesInfos"`
+}
+
+type failure struct {
+	status      int
+	code        int
+	description string
+}
+
+// Server is a fake BCCh web service. Its URL is meant to be given to
+// bcch.WithBaseURL.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	user     string
+	password string
+	series   map[string]Series
+	catalog  map[string][]SeriesInfo
+	latency  time.Duration
+	failures []failure
+	requests map[string]int
+	total    int
+}
+
+// NewServer starts a server loaded with the fixture series, accepting the
+// User and Password credentials.
+func NewServer() *Server {
+	s := &Server{
+		user:     User,
+		password: Password,
+		series:   make(map[string]Series),
+		catalog:  make(map[string][]SeriesInfo),
+		requests: make(map[string]int),
+	}
+	if err := s.loadFixtures(); err != nil {
+		panic(fmt.Sprintf("bcchtest: loading fixtures: %v", err))
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) loadFixtures() error {
+	files, err := fs.Glob(fixtures, "fixtures/series/*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		var resp response
+		if err := readFixture(file, &resp); err != nil {
+			return err
+		}
+		s.series[resp.Series.SeriesID] = *resp.Series
+	}
+
+	files, err = fs.Glob(fixtures, "fixtures/search/*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		var resp response
+		if err := readFixture(file, &resp); err != nil {
+			return err
+		}
+		frequency := strings.TrimSuffix(path.Base(file), ".json")
+		s.catalog[frequency] = resp.SeriesInfos
+	}
+	return nil
+}
+
+func readFixture(file string, v any) error {
+	dat, err := fixtures.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(dat, v); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// SetCredentials changes the credentials accepted by the server.
+func (s *Server) SetCredentials(user, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user, s.password = user, password
+}
+
+// AddSeries adds or replaces a series served by GetSeries.
+func (s *Server) AddSeries(series Series) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series[series.SeriesID] = series
+}
+
+// AddSeriesInfo adds a catalog entry returned by SearchSeries for the
+// frequency in info.FrequencyCode.
+func (s *Server) AddSeriesInfo(info SeriesInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	frequency := strings.ToUpper(info.FrequencyCode)
+	s.catalog[frequency] = append(s.catalog[frequency], info)
+}
+
+// SeriesIDs returns the IDs of the series served by GetSeries.
+func (s *Server) SeriesIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.series))
+	for id := range s.series {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext makes the next n requests fail with the given HTTP status.
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, failure{status: status})
+	}
+}
+
+// FailNextWithCode makes the next n requests answer 200 with the given
+// Codigo and Descripcion, as BCCh does for API errors.
+func (s *Server) FailNextWithCode(n, code int, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, failure{status: http.StatusOK, code: code, description: description})
+	}
+}
+
+// Requests returns how many requests reached the server for the given
+// series ID, or for SearchSeries with the given frequency.
+func (s *Server) Requests(seriesIDOrFrequency string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[seriesIDOrFrequency]
+}
+
+// TotalRequests returns how many requests reached the server.
+func (s *Server) TotalRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/SieteRestWS.ashx") {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	s.total++
+	if q.Get("function") == "SearchSeries" {
+		s.requests[strings.ToUpper(q.Get("frequency"))]++
+	} else {
+		s.requests[q.Get("timeseries")]++
+	}
+	latency := s.latency
+	var fail *failure
+	if len(s.failures) > 0 {
+		fail = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	validCredentials := q.Get("user") == s.user && q.Get("pass") == s.password
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case fail != nil && fail.status != http.StatusOK:
+		http.Error(w, http.StatusText(fail.status), fail.status)
+	case fail != nil:
+		writeJSON(w, response{Codigo: fail.code, Descripcion: fail.description})
+	case !validCredentials:
+		writeJSON(w, response{Codigo: CodeInvalidCredentials, Descripcion: descInvalidCredentials})
+	case q.Get("function") == "SearchSeries":
+		s.searchSeries(w, q.Get("frequency"))
+	case q.Get("function") == "GetSeries":
+		s.getSeries(w, q.Get("timeseries"), q.Get("firstdate"), q.Get("lastdate"))
+	default:
+		http.Error(w, "unknown function", http.StatusBadRequest)
+	}
+}
+
+func (s *Server) searchSeries(w http.ResponseWriter, frequency string) {
+	s.mu.Lock()
+	infos, ok := s.catalog[strings.ToUpper(frequency)]
+	s.mu.Unlock()
+	if !ok {
+		infos = []SeriesInfo{}
+	}
+	writeJSON(w, response{
+		Codigo:      CodeSuccess,
+		Descripcion: descSuccess,
+		Series:      &Series{},
+		SeriesInfos: infos,
+	})
+}
+
+func (s *Server) getSeries(w http.ResponseWriter, seriesID, firstDate, lastDate string) {
+	s.mu.Lock()
+	series, ok := s.series[seriesID]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, response{Codigo: CodeUnknownSeries, Descripcion: descUnknownSeries})
+		return
+	}
+
+	first, firstErr := parseRequestDate(firstDate)
+	last, lastErr := parseRequestDate(lastDate)
+	if firstErr != nil || lastErr != nil || (!first.IsZero() && !last.IsZero() && first.After(last)) {
+		writeJSON(w, response{Codigo: CodeInvalidDates, Descripcion: descInvalidDates})
+		return
+	}
+
+	obs := make([]Observation, 0, len(series.Obs))
+	for _, o := range series.Obs {
+		date, err := time.Parse(observationDateLayout, o.IndexDateString)
+		if err != nil {
+			continue
+		}
+		if (!first.IsZero() && date.Before(first)) || (!last.IsZero() && date.After(last)) {
+			continue
+		}
+		obs = append(obs, o)
+	}
+	series.Obs = obs
+
+	writeJSON(w, response{
+		Codigo:      CodeSuccess,
+		Descripcion: descSuccess,
+		Series:      &series,
+		SeriesInfos: []SeriesInfo{},
+	})
+}
+
+func parseRequestDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(requestDateLayout, s)
+}
+
+func writeJSON(w http.ResponseWriter, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(payload) // #nosec G104 -- test server, client sees a truncated body
+}
@@ -0,0 +1,30 @@
+package bcch
+
+import "time"
+
+// Cache stores raw API responses under a key built from the request
+// parameters, never including the credentials. A nil Cache given to
+// NewClient disables caching.
+//
+// This package ships no Cache implementation: the file cache of the bcch CLI
+// is internal to the module, so callers provide their own, backed by memory,
+// files or a shared store. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key unless it has expired.
+	Get(key string) ([]byte, bool)
+	// GetStale returns the value stored under key even if it has expired,
+	// along with the time it was stored. It backs the offline mode.
+	GetStale(key string) ([]byte, time.Time, bool)
+	// Add stores value under key.
+	Add(key string, value []byte) error
+	// Interval returns the duration after which entries expire.
+	Interval() time.Duration
+}
+
+// noCache is the Cache used when NewClient is given none.
+type noCache struct{}
+
+func (noCache) Get(string) ([]byte, bool)                 { return nil, false }
+func (noCache) GetStale(string) ([]byte, time.Time, bool) { return nil, time.Time{}, false }
+func (noCache) Add(string, []byte) error                  { return nil }
+func (noCache) Interval() time.Duration                   { return 0 }
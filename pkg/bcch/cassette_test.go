@@ -0,0 +1,115 @@
+package bcch_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+// TestCassetteSeries replays a cassette written by hand after the format of
+// BCCh responses, not recorded from the service, to cover edge cases such as
+// NaN values and empty ranges. It is always replayed, so that BCCH_RECORD
+// never overwrites it with real payloads lacking those cases.
+func TestCassetteSeries(t *testing.T) {
+	recorder, err := bcchtest.NewRecorder("testdata/cassettes/series_handwritten.json", bcchtest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := bcch.NewClient(time.Minute, nil, bcch.WithTransport(recorder))
+	c.AuthConfig = bcch.AuthConfig{User: "user", Password: "password"}
+
+	t.Run("NaN values", func(t *testing.T) {
+		resp, err := c.GetSeriesData("F073.TCO.PRE.Z.D", "2024-01-02", "2024-01-05")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s, err := resp.ToSeries()
+		if err != nil {
+			t.Fatalf("unexpected error converting series: %v", err)
+		}
+		if len(s.Observations) == 0 {
+			t.Fatal("expected observations")
+		}
+		last := s.Observations[len(s.Observations)-1]
+		if !last.Missing {
+			t.Errorf("expected NaN observation on %v to be missing, got %v", last.Date, last.Value)
+		}
+	})
+
+	t.Run("empty observations", func(t *testing.T) {
+		resp, err := c.GetSeriesData("F049.DES.TAS.INE.10.M", "2030-01-01", "2030-12-31")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s, err := resp.ToSeries()
+		if err != nil {
+			t.Fatalf("unexpected error converting series: %v", err)
+		}
+		if len(s.Observations) != 0 {
+			t.Errorf("expected no observations, got %v", len(s.Observations))
+		}
+	})
+}
+
+const recordedCassette = "testdata/cassettes/series_recorded.json"
+
+// TestCassetteRecorded replays payloads recorded from the real BCCh web
+// service, so that the client is checked against what BCCh actually sends.
+// Record the cassette with:
+//
+//	BCCH_RECORD=1 BCCH_USER=... BCCH_PASSWORD=... go test ./pkg/bcch -run CassetteRecorded
+//
+// It is skipped until the cassette has been recorded.
+func TestCassetteRecorded(t *testing.T) {
+	mode := bcchtest.ModeFromEnv()
+	if _, err := os.Stat(recordedCassette); mode == bcchtest.ModeReplay && errors.Is(err, os.ErrNotExist) {
+		t.Skipf("%s not recorded yet, record it with %s=1", recordedCassette, bcchtest.EnvRecord)
+	}
+
+	auth := bcch.AuthConfig{User: "user", Password: "password"}
+	if mode == bcchtest.ModeRecord {
+		auth = bcch.AuthConfig{User: os.Getenv(bcch.EnvUser), Password: os.Getenv(bcch.EnvPassword)}
+		if auth.User == "" || auth.Password == "" {
+			t.Fatalf("recording needs %s and %s", bcch.EnvUser, bcch.EnvPassword)
+		}
+	}
+	c := bcch.NewClient(time.Minute, nil, bcch.WithTransport(bcchtest.UseCassette(t, recordedCassette)))
+	c.AuthConfig = auth
+
+	t.Run("series", func(t *testing.T) {
+		resp, err := c.GetSeriesData("F073.TCO.PRE.Z.D", "2024-01-02", "2024-01-05")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s, err := resp.ToSeries()
+		if err != nil {
+			t.Fatalf("unexpected error converting series: %v", err)
+		}
+		if s.ID != "F073.TCO.PRE.Z.D" || s.SpanishTitle == "" {
+			t.Errorf("unexpected series %v (%q)", s.ID, s.SpanishTitle)
+		}
+		if len(s.Observations) == 0 {
+			t.Fatal("expected observations")
+		}
+		first, last := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+		for _, obs := range s.Observations {
+			if obs.Date.Before(first) || obs.Date.After(last) {
+				t.Errorf("observation on %v outside the requested range", obs.Date)
+			}
+			if !obs.Missing && obs.Value <= 0 {
+				t.Errorf("unexpected exchange rate %v on %v", obs.Value, obs.Date)
+			}
+		}
+	})
+
+	t.Run("unknown series", func(t *testing.T) {
+		_, err := c.GetSeriesData("NOT.A.SERIES", "2024-01-02", "2024-01-05")
+		if !errors.Is(err, bcch.ErrUnknownSeries) {
+			t.Errorf("expected unknown series error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package bcch
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the BCCh web service. Create it with NewClient and set
+// AuthConfig before making requests.
+type Client struct {
+	cache      Cache
+	httpClient http.Client
+	baseURL    string
+	userAgent  string
+	proxy      *url.URL
+	logger     *log.Logger
+	limiter    *rateLimiter
+	flights    flightGroup
+	AuthConfig AuthConfig
+	// Offline makes the client answer only from the cache, even with
+	// expired entries, and never touch the network.
+	Offline bool
+	// RetryPolicy controls the retries of transient API failures.
+	RetryPolicy RetryPolicy
+}
+
+// Option configures a Client created with NewClient.
+type Option func(*Client)
+
+// WithRateLimit limits every request made through the client, including
+// retries and concurrent fan-outs, to requestsPerSecond with bursts of up
+// to burst requests. A non-positive rate disables the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		if requestsPerSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithBaseURL points the client at a mirror or test server of the BCCh web
+// service instead of DefaultBaseURL. An empty baseURL keeps the default.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL == "" {
+			return
+		}
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		c.baseURL = baseURL
+	}
+}
+
+// WithTransport sends the requests through rt instead of
+// http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithProxy sends the requests through the given proxy instead of the one
+// in the HTTP_PROXY/HTTPS_PROXY environment variables. It applies to the
+// default transport and to transports given with WithTransport that are an
+// *http.Transport.
+func WithProxy(proxy *url.URL) Option {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// WithLogger reports to logger the warnings that do not fail a request,
+// like expired responses served in offline mode or responses that could not
+// be cached. Warnings are discarded by default.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// NewClient returns a client whose requests time out after timeout. Responses
+// are cached in cache, which may be nil to disable caching.
+func NewClient(timeout time.Duration, cache Cache, opts ...Option) *Client {
+	if cache == nil {
+		cache = noCache{}
+	}
+	c := &Client{
+		cache: cache,
+		httpClient: http.Client{
+			Timeout: timeout,
+		},
+		baseURL:     DefaultBaseURL,
+		userAgent:   DefaultUserAgent,
+		AuthConfig:  AuthConfig{},
+		RetryPolicy: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.proxy != nil {
+		rt := c.httpClient.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		if t, ok := rt.(*http.Transport); ok {
+			t = t.Clone()
+			t.Proxy = http.ProxyURL(c.proxy)
+			c.httpClient.Transport = t
+		}
+	}
+	return c
+}
+
+// warnf reports a warning to the logger set with WithLogger, if any.
+func (c *Client) warnf(format string, args ...any) {
+	if c.logger != nil {
+		c.logger.Printf("warning: "+format, args...)
+	}
+}
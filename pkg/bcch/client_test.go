@@ -0,0 +1,105 @@
+package bcch_test
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+// recordingTransport records the User-Agent of the requests it forwards.
+type recordingTransport struct {
+	mu     sync.Mutex
+	agents []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.agents = append(rt.agents, req.Header.Get("User-Agent"))
+	rt.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransportAndUserAgent(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	for _, c := range []struct {
+		opts []bcch.Option
+		want string
+	}{
+		{want: bcch.DefaultUserAgent},
+		{opts: []bcch.Option{bcch.WithUserAgent("bcch-test/1.0")}, want: "bcch-test/1.0"},
+	} {
+		rt := &recordingTransport{}
+		opts := append([]bcch.Option{bcch.WithBaseURL(srv.URL), bcch.WithTransport(rt)}, c.opts...)
+		client := bcch.NewClient(time.Second, nil, opts...)
+		client.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+
+		if _, err := client.GetSeriesData(dailySeries, "2024-01-02", "2024-01-03"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rt.agents) != 1 || rt.agents[0] != c.want {
+			t.Errorf("expected one request through the transport with User-Agent %q, got %q", c.want, rt.agents)
+		}
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	proxy := bcchtest.NewServer()
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the base URL does not resolve, so requests only succeed through the proxy
+	for _, opts := range [][]bcch.Option{
+		{bcch.WithProxy(proxyURL)},
+		{bcch.WithProxy(proxyURL), bcch.WithTransport(&http.Transport{})},
+	} {
+		opts = append(opts, bcch.WithBaseURL("http://bcch.invalid/SieteRestWS/"))
+		client := bcch.NewClient(time.Second, nil, opts...)
+		client.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+		client.RetryPolicy.MaxAttempts = 1
+
+		requests := proxy.TotalRequests()
+		if _, err := client.GetSeriesData(dailySeries, "2024-01-02", "2024-01-03"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := proxy.TotalRequests() - requests; got != 1 {
+			t.Errorf("expected 1 request through the proxy, got %v", got)
+		}
+	}
+}
+
+func TestCacheKeyIncludesBaseURL(t *testing.T) {
+	production := bcchtest.NewServer()
+	defer production.Close()
+	mirror := bcchtest.NewServer()
+	defer mirror.Close()
+
+	cache := bcchcache.NewCache(time.Minute)
+	newClient := func(srv *bcchtest.Server) *bcch.Client {
+		c := bcch.NewClient(time.Second, &cache, bcch.WithBaseURL(srv.URL))
+		c.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+		return c
+	}
+
+	for _, srv := range []*bcchtest.Server{production, mirror, production} {
+		if _, err := newClient(srv).GetSeriesData(dailySeries, "2024-01-02", "2024-01-03"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := production.Requests(dailySeries); got != 1 {
+		t.Errorf("expected 1 request to production, the second one served from the cache, got %v", got)
+	}
+	if got := mirror.Requests(dailySeries); got != 1 {
+		t.Errorf("expected the mirror not to share cached responses with production, got %v requests", got)
+	}
+}
@@ -0,0 +1,259 @@
+package bcch
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables selecting the key of encrypted credential files.
+const (
+	EnvPassphrase = "BCCH_PASSPHRASE" // #nosec G101
+	EnvKeyFile    = "BCCH_KEY_FILE"
+)
+
+const (
+	keyFile        = "credentials.key"
+	cipherName     = "aes-256-gcm"
+	kdfScrypt      = "scrypt"
+	kdfKeyFile     = "keyfile"
+	keySize        = 32
+	saltSize       = 16
+	scryptN        = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	keyFilePerm    = 0600
+	credentialPerm = 0600
+)
+
+// ErrCredentialsEncrypted is returned when the credentials file is
+// encrypted and no passphrase or key file was provided to read it.
+var ErrCredentialsEncrypted = errors.New("credentials file is encrypted, set " + EnvPassphrase + " or " + EnvKeyFile)
+
+// CredentialStore reads and writes the content of the credentials file.
+type CredentialStore interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+}
+
+// PlaintextStore keeps the credentials file as plain JSON.
+type PlaintextStore struct{}
+
+func (PlaintextStore) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the credentials file chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted(data) {
+		return nil, ErrCredentialsEncrypted
+	}
+	return data, nil
+}
+
+func (PlaintextStore) WriteFile(path string, data []byte) error {
+	return writeFileAtomic(path, data, credentialPerm)
+}
+
+// EncryptedStore encrypts the credentials file with AES-GCM. Plaintext
+// files are still read, and encrypted on the next write.
+type EncryptedStore struct {
+	kdf string
+	// deriveKey returns the AES key for the given salt.
+	deriveKey func(salt []byte) ([]byte, error)
+}
+
+// NewPassphraseStore returns a store whose key is derived from passphrase
+// with scrypt.
+func NewPassphraseStore(passphrase string) *EncryptedStore {
+	return &EncryptedStore{
+		kdf: kdfScrypt,
+		deriveKey: func(salt []byte) ([]byte, error) {
+			return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+		},
+	}
+}
+
+// NewKeyFileStore returns a store whose key is read from the file at path.
+// Any file content works as key material; use CreateKeyFile to generate a
+// random one.
+func NewKeyFileStore(path string) *EncryptedStore {
+	return &EncryptedStore{
+		kdf: kdfKeyFile,
+		deriveKey: func(salt []byte) ([]byte, error) {
+			material, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return nil, fmt.Errorf("error reading key file: %w", err)
+			}
+			if len(material) == 0 {
+				return nil, errors.New("key file is empty")
+			}
+			key := sha256.Sum256(append(salt, material...))
+			return key[:], nil
+		},
+	}
+}
+
+// CreateKeyFile writes a random key to path, readable only by the current
+// user. An existing key file is left untouched.
+func CreateKeyFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating key file directory: %w", err)
+	}
+	return writeFileAtomic(path, key, keyFilePerm)
+}
+
+// DefaultKeyFilePath returns the key file next to the default credentials
+// file.
+func DefaultKeyFilePath() (string, error) {
+	path, err := DefaultCredentialsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), keyFile), nil
+}
+
+// DefaultCredentialStore returns the store selected by the environment:
+// EnvPassphrase, then EnvKeyFile, then the default key file if it exists,
+// falling back to PlaintextStore.
+func DefaultCredentialStore() CredentialStore {
+	if passphrase := os.Getenv(EnvPassphrase); passphrase != "" {
+		return NewPassphraseStore(passphrase)
+	}
+	if path := os.Getenv(EnvKeyFile); path != "" {
+		return NewKeyFileStore(path)
+	}
+	if path, err := DefaultKeyFilePath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			return NewKeyFileStore(path)
+		}
+	}
+	return PlaintextStore{}
+}
+
+// encryptedFile is the on-disk representation of an encrypted credentials
+// file.
+type encryptedFile struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s *EncryptedStore) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the credentials file chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(data) {
+		return data, nil
+	}
+
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return nil, fmt.Errorf("error during unmarshal of encrypted credentials (JSON): %w", err)
+	}
+	if ef.KDF != s.kdf {
+		return nil, fmt.Errorf("credentials were encrypted with a %s key, not a %s one", ef.KDF, s.kdf)
+	}
+	gcm, err := s.gcm(ef.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, ef.Nonce, ef.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("error decrypting credentials: wrong passphrase or key file")
+	}
+	return plaintext, nil
+}
+
+func (s *EncryptedStore) WriteFile(path string, data []byte) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := s.gcm(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	encrypted, err := json.MarshalIndent(encryptedFile{
+		Cipher:     cipherName,
+		KDF:        s.kdf,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, data, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, encrypted, credentialPerm)
+}
+
+func (s *EncryptedStore) gcm(salt []byte) (cipher.AEAD, error) {
+	key, err := s.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncrypted reports whether data holds an encrypted credentials file.
+func isEncrypted(data []byte) bool {
+	if !bytes.Contains(data, []byte(`"cipher"`)) {
+		return false
+	}
+	var ef encryptedFile
+	return json.Unmarshal(data, &ef) == nil && ef.Cipher == cipherName
+}
+
+// writeFileAtomic writes data to a temporary file with the given
+// permissions and renames it into place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // #nosec G104 -- no-op once renamed
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,65 @@
+package bcch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, keyFile)
+	if err := CreateKeyFile(keyPath); err != nil {
+		t.Fatalf("unexpected error creating key file: %v", err)
+	}
+
+	cases := map[string]struct {
+		store CredentialStore
+		wrong CredentialStore
+	}{
+		"passphrase": {
+			store: NewPassphraseStore("correct horse"),
+			wrong: NewPassphraseStore("battery staple"),
+		},
+		"key file": {
+			store: NewKeyFileStore(keyPath),
+			wrong: NewPassphraseStore("correct horse"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), credentialsFile)
+			saved := AuthConfig{User: "user", Password: "s3cret-pass", Path: path, Store: c.store}
+			if err := saved.Save(); err != nil {
+				t.Fatalf("unexpected error saving credentials: %v", err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error reading credentials file: %v", err)
+			}
+			if strings.Contains(string(raw), "s3cret-pass") {
+				t.Errorf("expected password to be encrypted on disk")
+			}
+
+			loaded := AuthConfig{Path: path, Store: c.store}
+			if err := loaded.Load(); err != nil {
+				t.Fatalf("unexpected error loading credentials: %v", err)
+			}
+			if loaded.Password != "s3cret-pass" {
+				t.Errorf("expected decrypted password, got %q", loaded.Password)
+			}
+
+			if err := (&AuthConfig{Path: path, Store: c.wrong}).Load(); err == nil {
+				t.Errorf("expected error loading with the wrong key")
+			}
+			err = (&AuthConfig{Path: path, Store: PlaintextStore{}}).Load()
+			if !errors.Is(err, ErrCredentialsEncrypted) {
+				t.Errorf("expected ErrCredentialsEncrypted loading without key, got %v", err)
+			}
+		})
+	}
+}
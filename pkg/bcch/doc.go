@@ -0,0 +1,34 @@
+// Package bcch is a client for the Banco Central de Chile (BCCh) SieteRestWS
+// web service, the statistical series API behind the bcch CLI.
+//
+// Create a Client with NewClient, set its credentials and fetch series:
+//
+//	client := bcch.NewClient(time.Minute, nil)
+//	client.AuthConfig = bcch.AuthConfig{User: user, Password: password}
+//	resp, err := client.GetSeriesDataContext(ctx, "F073.TCO.PRE.Z.D", "2024-01-01", "")
+//	if err != nil {
+//		return err
+//	}
+//	series, err := resp.ToSeries()
+//
+// Credentials can also be read from the environment or the credentials file
+// shared with the CLI through AuthConfig.Load.
+//
+// Options given to NewClient set the base URL, HTTP transport, user agent,
+// proxy, rate limit and the logger warnings are reported to; the package
+// logs nothing by default. Responses are cached in the Cache given to
+// NewClient, if any; the package has no Cache implementation of its own, so
+// callers bring one, as in the Cache example. Transient failures are retried
+// following Client.RetryPolicy, and concurrent requests for the same series
+// are coalesced.
+//
+// Errors returned by the BCCh service match the sentinel errors of this
+// package, such as ErrInvalidCredentials or ErrUnknownSeries, with
+// errors.Is, and never contain the credentials.
+//
+// The bcchtest package provides a fake BCCh server and a record/replay
+// transport to test code built on this package.
+//
+// This package follows the semantic versioning of the module: breaking
+// changes only happen in a new major version.
+package bcch
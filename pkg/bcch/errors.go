@@ -0,0 +1,122 @@
+package bcch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotCached is matched by errors returned in offline mode when a
+// request has no cached response.
+var ErrNotCached = errors.New("response not cached")
+
+// NotCachedError is returned in offline mode when the response for a
+// request is not in the cache.
+type NotCachedError struct {
+	Query string
+}
+
+func (e *NotCachedError) Error() string {
+	return fmt.Sprintf("offline mode: no cached response for %q", e.Query)
+}
+
+func (e *NotCachedError) Is(target error) bool {
+	return target == ErrNotCached
+}
+
+// Errors matched with errors.Is against the error codes BCCh reports in the
+// Codigo field of an otherwise successful response, and against HTTP
+// error statuses.
+var (
+	ErrInvalidCredentials = errors.New("invalid BCCh credentials")
+	ErrAccountLocked      = errors.New("BCCh account locked or API access not enabled")
+	ErrUnknownSeries      = errors.New("unknown series")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrRateLimited        = errors.New("rate limited by BCCh")
+	ErrUpstream           = errors.New("BCCh upstream error")
+)
+
+// Error codes reported by BCCh in Codigo.
+const (
+	codeSuccess            = 0
+	codeInvalidDates       = -1
+	codeInvalidCredentials = -5
+	codeUnknownSeries      = -50
+)
+
+// APIError is returned when BCCh reports a non zero Codigo. Its Unwrap
+// returns one of the Err* sentinels describing the kind of failure.
+type APIError struct {
+	Code        int
+	Description string
+	Kind        error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v (BCCh code %d: %s)", e.Kind, e.Code, e.Description)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// newAPIError returns nil for a successful Codigo and an *APIError
+// otherwise. Known codes are classified by their value alone, except for
+// rejected credentials, whose description tells a locked account apart.
+// Unknown codes are classified by a few unambiguous phrases of their
+// description, falling back to ErrUpstream, so that an upstream failure is
+// never mistaken for a problem with the request.
+func newAPIError(code int, description string) error {
+	if code == codeSuccess {
+		return nil
+	}
+
+	var kind error
+	desc := strings.ToLower(description)
+	switch code {
+	case codeInvalidCredentials:
+		kind = ErrInvalidCredentials
+		if isAccountLocked(desc) {
+			kind = ErrAccountLocked
+		}
+	case codeUnknownSeries:
+		kind = ErrUnknownSeries
+	case codeInvalidDates:
+		kind = ErrInvalidDateRange
+	default:
+		switch {
+		case isAccountLocked(desc):
+			kind = ErrAccountLocked
+		case containsAny(desc, "limit exceeded", "límite excedido", "too many requests"):
+			kind = ErrRateLimited
+		default:
+			kind = ErrUpstream
+		}
+	}
+
+	return &APIError{
+		Code:        code,
+		Description: description,
+		Kind:        kind,
+	}
+}
+
+// isAccountLocked reports whether the description says that the account is
+// locked or has no API access.
+func isAccountLocked(desc string) bool {
+	return containsAny(desc,
+		"usuario bloqueado", "cuenta bloqueada", "usuario inactivo", "cuenta inactiva",
+		"account locked", "account blocked", "account inactive", "user locked", "user blocked",
+		"api access not enabled", "acceso a la api no habilitado",
+	)
+}
+
+// containsAny reports whether s contains any of the phrases.
+func containsAny(s string, phrases ...string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(s, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package bcch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	cases := []struct {
+		code        int
+		description string
+		want        error
+	}{
+		{code: 0, description: "Success", want: nil},
+		{code: -5, description: "Invalid username or password", want: ErrInvalidCredentials},
+		{code: -50, description: "Unknown", want: ErrUnknownSeries},
+		{code: -5, description: "Usuario bloqueado", want: ErrAccountLocked},
+		{code: -1, description: "Fecha inicial mayor a fecha final", want: ErrInvalidDateRange},
+		{code: -50, description: "The series does not exist", want: ErrUnknownSeries},
+		{code: -10, description: "Request limit exceeded", want: ErrRateLimited},
+		{code: -99, description: "Internal error", want: ErrUpstream},
+		// unknown codes are not guessed from words of the description
+		{code: -99, description: "Invalid date of update", want: ErrUpstream},
+		{code: -99, description: "Error updating the series catalog", want: ErrUpstream},
+		{code: -99, description: "Error de la serie en el servidor", want: ErrUpstream},
+		{code: -99, description: "Usuario sin contraseña vigente", want: ErrUpstream},
+		{code: -99, description: "Request blocked by the upstream firewall", want: ErrUpstream},
+		{code: -99, description: "Series inactive since 2020", want: ErrUpstream},
+		{code: -99, description: "Cuenta bloqueada", want: ErrAccountLocked},
+		// a locked account is only read from credential and unknown codes
+		{code: -50, description: "Series locked for maintenance", want: ErrUnknownSeries},
+		{code: -1, description: "Account locked dates", want: ErrInvalidDateRange},
+		{code: -5, description: "Password blocked characters", want: ErrInvalidCredentials},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("test case %v", i), func(t *testing.T) {
+			err := newAPIError(c.code, c.description)
+			if c.want == nil {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			wrapped := fmt.Errorf("series UF: %w", err)
+			if !errors.Is(wrapped, c.want) {
+				t.Errorf("expected %v to match %v", err, c.want)
+			}
+			var apiErr *APIError
+			if !errors.As(wrapped, &apiErr) || apiErr.Code != c.code {
+				t.Errorf("expected *APIError with code %v, got %v", c.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package bcch_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+type memoryEntry struct {
+	createdAt time.Time
+	value     []byte
+}
+
+// memoryCache is a minimal bcch.Cache keeping responses in memory.
+type memoryCache struct {
+	mu       sync.Mutex
+	entries  map[string]memoryEntry
+	interval time.Duration
+}
+
+func newMemoryCache(interval time.Duration) *memoryCache {
+	return &memoryCache{entries: make(map[string]memoryEntry), interval: interval}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	value, createdAt, ok := c.GetStale(key)
+	if !ok || time.Since(createdAt) > c.interval {
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *memoryCache) GetStale(key string) ([]byte, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry.value, entry.createdAt, ok
+}
+
+func (c *memoryCache) Add(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = memoryEntry{createdAt: time.Now(), value: value}
+	return nil
+}
+
+func (c *memoryCache) Interval() time.Duration {
+	return c.interval
+}
+
+// Callers provide their own Cache; this one keeps responses in memory for
+// an hour, so the second request never reaches the server.
+func ExampleCache() {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	client := bcch.NewClient(time.Minute, newMemoryCache(time.Hour), bcch.WithBaseURL(srv.URL))
+	client.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+
+	for range 2 {
+		if _, err := client.GetSeriesData("F073.TCO.PRE.Z.D", "2024-01-02", "2024-01-05"); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	fmt.Println("requests to the server:", srv.TotalRequests())
+	// Output: requests to the server: 1
+}
@@ -0,0 +1,63 @@
+package bcch
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup coalesces concurrent fetches of the same request, so callers
+// asking for the same series and date range share one network round trip.
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// do runs fn once for every set of concurrent callers using the same key.
+// Callers stop waiting when their own ctx is done. If the call failed only
+// because the context of the caller that started it was cancelled, the
+// remaining callers run fn again with their own context.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) ([]byte, error)) ([]byte, error) {
+	for {
+		g.mu.Lock()
+		if g.calls == nil {
+			g.calls = make(map[string]*flight)
+		}
+		f, ok := g.calls[key]
+		if !ok {
+			f = &flight{done: make(chan struct{})}
+			g.calls[key] = f
+			g.mu.Unlock()
+
+			f.body, f.err = fn(ctx)
+
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(f.done)
+			return f.body, f.err
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if isContextError(f.err) && ctx.Err() == nil {
+			continue
+		}
+		return f.body, f.err
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,80 @@
+package bcch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupCoalesces(t *testing.T) {
+	var g flightGroup
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	fn := func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("body"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, err := g.do(context.Background(), "key", fn)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = string(body)
+		}()
+	}
+
+	// let every caller join the flight before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 call, got %v", got)
+	}
+	for i, body := range results {
+		if body != "body" {
+			t.Errorf("expected caller %v to get body, got %q", i, body)
+		}
+	}
+}
+
+func TestFlightGroupLeaderCancelled(t *testing.T) {
+	var g flightGroup
+	var calls atomic.Int32
+	started := make(chan struct{})
+
+	fn := func(ctx context.Context) ([]byte, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []byte("body"), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go g.do(ctx, "key", fn)
+	<-started
+
+	done := make(chan []byte)
+	go func() {
+		body, _ := g.do(context.Background(), "key", fn)
+		done <- body
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if body := <-done; string(body) != "body" {
+		t.Errorf("expected follower to fetch again after leader cancellation, got %q", body)
+	}
+}
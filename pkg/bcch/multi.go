@@ -0,0 +1,175 @@
+package bcch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// FetchMode selects how GetMultipleSeriesData handles failed series.
+type FetchMode int
+
+const (
+	// BestEffort fetches every series, whether others failed or not.
+	BestEffort FetchMode = iota
+	// FailFast cancels the remaining series once one fails.
+	FailFast
+)
+
+// FetchOptions tunes GetMultipleSeriesData.
+type FetchOptions struct {
+	// MaxConcurrency is the number of series fetched at once, 3 when not
+	// positive.
+	MaxConcurrency int
+	Mode           FetchMode
+	// OnResult, when set, receives every result as soon as its series
+	// completes, in completion order. Calls are never concurrent.
+	OnResult func(SeriesResult)
+}
+
+// FetchStatus is the outcome of fetching one series of a
+// GetMultipleSeriesData call.
+type FetchStatus int
+
+const (
+	// FetchOK marks a series fetched successfully.
+	FetchOK FetchStatus = iota
+	// FetchFailed marks a series whose request failed.
+	FetchFailed
+	// FetchCanceled marks a series not fetched because the context was done
+	// or, in FailFast mode, another series failed.
+	FetchCanceled
+)
+
+func (s FetchStatus) String() string {
+	switch s {
+	case FetchOK:
+		return "ok"
+	case FetchFailed:
+		return "failed"
+	case FetchCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// SeriesResult is the outcome of fetching one series of a
+// GetMultipleSeriesData call.
+type SeriesResult struct {
+	// Index is the position of the series in the requested IDs.
+	Index    int
+	SeriesID string
+	Status   FetchStatus
+	// Data is only set when Status is FetchOK.
+	Data SeriesDataResp
+	// Duration is the time spent fetching the series, including waits for
+	// a concurrency slot, the rate limit and retries.
+	Duration time.Duration
+	// Err is nil when Status is FetchOK. Canceled series report the cause
+	// of the cancellation, ErrFetchAborted in FailFast mode.
+	Err error
+}
+
+// ErrFetchAborted is reported by the series left unfetched in FailFast mode
+// after another series failed.
+var ErrFetchAborted = errors.New("fetch aborted after another series failed")
+
+const defaultMaxConcurrency = 3
+
+// GetMultipleSeriesData fetches several series concurrently. See
+// GetMultipleSeriesDataContext.
+func (c *Client) GetMultipleSeriesData(seriesIDs []string, firstDate, lastDate string, opts *FetchOptions) ([]SeriesResult, error) {
+	return c.GetMultipleSeriesDataContext(context.Background(), seriesIDs, firstDate, lastDate, opts)
+}
+
+// GetMultipleSeriesDataContext fetches several series concurrently and
+// returns one result per requested ID, in the same order. The returned
+// error joins the errors of the failed series and, when ctx is done before
+// every series was fetched, the cause of ctx, so it is nil only when every
+// series was fetched. When ctx is done no more series are launched and
+// in-flight requests are aborted.
+func (c *Client) GetMultipleSeriesDataContext(ctx context.Context, seriesIDs []string, firstDate, lastDate string, opts *FetchOptions) ([]SeriesResult, error) {
+	if opts == nil {
+		opts = &FetchOptions{}
+	}
+	maxConc := opts.MaxConcurrency
+	if maxConc <= 0 {
+		maxConc = defaultMaxConcurrency
+	}
+
+	fetchCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]SeriesResult, len(seriesIDs))
+	sem := make(chan struct{}, maxConc)
+	var notify sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, seriesID := range seriesIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			result := c.fetchOne(fetchCtx, sem, id, firstDate, lastDate)
+			result.Index = i
+			results[i] = result
+
+			if result.Status == FetchFailed && opts.Mode == FailFast {
+				cancel(ErrFetchAborted)
+			}
+			if opts.OnResult != nil {
+				notify.Lock()
+				defer notify.Unlock()
+				opts.OnResult(result)
+			}
+		}(i, seriesID)
+	}
+	wg.Wait()
+
+	var errs []error
+	canceled := false
+	for _, result := range results {
+		switch result.Status {
+		case FetchFailed:
+			errs = append(errs, result.Err)
+		case FetchCanceled:
+			canceled = true
+		}
+	}
+	// series aborted in FailFast mode are explained by the failed ones
+	if canceled && ctx.Err() != nil {
+		errs = append(errs, context.Cause(ctx))
+	}
+	return results, errors.Join(errs...)
+}
+
+// fetchOne fetches a single series once a slot of sem is free.
+func (c *Client) fetchOne(ctx context.Context, sem chan struct{}, seriesID, firstDate, lastDate string) SeriesResult {
+	start := time.Now()
+	result := SeriesResult{SeriesID: seriesID}
+
+	select {
+	case sem <- struct{}{}: // acquire slot
+	case <-ctx.Done():
+		result.Status = FetchCanceled
+		result.Err = context.Cause(ctx)
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer func() { <-sem }() // release slot
+
+	data, err := c.GetSeriesDataContext(ctx, seriesID, firstDate, lastDate)
+	result.Duration = time.Since(start)
+	switch {
+	case err == nil:
+		result.Status = FetchOK
+		result.Data = data
+	case ctx.Err() != nil:
+		result.Status = FetchCanceled
+		result.Err = context.Cause(ctx)
+	default:
+		result.Status = FetchFailed
+		result.Err = err
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package bcch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(1, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error waiting: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected burst requests not to wait, waited %v", elapsed)
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	const rate = 20
+	l := newRateLimiter(rate, 1)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.wait(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	// the first request uses the burst, the other four wait 1/rate each
+	if elapsed, want := time.Since(start), 4*time.Second/rate; elapsed < want-10*time.Millisecond {
+		t.Errorf("expected concurrent requests to take at least %v, took %v", want, elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := newRateLimiter(0.1, 1)
+	l.wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Errorf("expected error waiting with a cancelled context")
+	}
+}
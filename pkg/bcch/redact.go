@@ -0,0 +1,83 @@
+package bcch
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// redactedError hides the credentials from the message of the wrapped
+// error while keeping it available to errors.Is and errors.As.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redact returns err with every occurrence of the credentials removed from
+// its message.
+func (c *Client) redact(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := c.redactString(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, msg: msg}
+}
+
+// minSecretLen is the shortest credential redacted outside of URL query
+// parameters; shorter ones would mangle unrelated parts of the message.
+const minSecretLen = 4
+
+// redactString removes the credentials, raw and URL-escaped, from s.
+func (c *Client) redactString(s string) string {
+	secrets := map[string]string{
+		"user": c.AuthConfig.User,
+		"pass": c.AuthConfig.Password,
+	}
+	for param, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, param+"="+url.QueryEscape(secret), param+"="+redacted)
+		if len(secret) >= minSecretLen {
+			s = strings.ReplaceAll(s, secret, redacted)
+			s = strings.ReplaceAll(s, url.QueryEscape(secret), redacted)
+		}
+	}
+	return s
+}
+
+// redactURLError replaces the credentials in the URL reported by errors
+// from http.Client.Do.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		urlErr.URL = redacted
+		return err
+	}
+	params := u.Query()
+	for _, key := range []string{"user", "pass"} {
+		if params.Has(key) {
+			params.Set(key, redacted)
+		}
+	}
+	u.RawQuery = params.Encode()
+	urlErr.URL = u.String()
+	return err
+}
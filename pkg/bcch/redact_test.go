@@ -0,0 +1,98 @@
+package bcch
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused to " + req.URL.String())
+}
+
+func TestErrorsHideCredentials(t *testing.T) {
+	c := NewClient(time.Second, nil, WithTransport(failingTransport{}))
+	c.RetryPolicy.MaxAttempts = 1
+	c.AuthConfig = AuthConfig{User: "someone@example.com", Password: "s3cr&t pass"}
+
+	_, err := c.GetSeriesData("F073.TCO.PRE.Z.D", "2024-01-01", "")
+	if err == nil {
+		t.Fatal("expected error from failing transport")
+	}
+	msg := err.Error()
+	for _, secret := range []string{"someone", "s3cr"} {
+		if strings.Contains(msg, secret) {
+			t.Errorf("expected error to hide %q, got %v", secret, msg)
+		}
+	}
+	if !strings.Contains(msg, "timeseries=F073.TCO.PRE.Z.D") {
+		t.Errorf("expected error to keep the request parameters, got %v", msg)
+	}
+}
+
+func TestRedactShortCredentials(t *testing.T) {
+	cases := map[string]struct {
+		user, password string
+		msg            string
+		want           string
+	}{
+		"short password in query": {
+			password: "ab1",
+			msg:      "Get https://si3.bcentral.cl/SieteRestWS/SieteRestWS.ashx?pass=ab1&timeseries=UF",
+			want:     "Get https://si3.bcentral.cl/SieteRestWS/SieteRestWS.ashx?pass=REDACTED&timeseries=UF",
+		},
+		"one character password in query": {
+			password: "x",
+			msg:      "Get https://si3.bcentral.cl/?timeseries=UF&pass=x",
+			want:     "Get https://si3.bcentral.cl/?timeseries=UF&pass=REDACTED",
+		},
+		"escaped short password in query": {
+			password: "a&b",
+			msg:      "Get https://si3.bcentral.cl/?pass=a%26b&timeseries=UF",
+			want:     "Get https://si3.bcentral.cl/?pass=REDACTED&timeseries=UF",
+		},
+		"short password outside query": {
+			password: "ab1",
+			msg:      "series ab1 not found: dial tcp: lookup tab1.example",
+			want:     "series ab1 not found: dial tcp: lookup tab1.example",
+		},
+		"short credentials in and outside query": {
+			user:     "ana",
+			password: "x",
+			msg:      "unexpected EOF reading x for ana from ?user=ana&pass=x",
+			want:     "unexpected EOF reading x for ana from ?user=REDACTED&pass=REDACTED",
+		},
+		"long password anywhere": {
+			password: "s3cret",
+			msg:      "invalid password s3cret in ?pass=s3cret",
+			want:     "invalid password REDACTED in ?pass=REDACTED",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(time.Second, nil)
+			c.AuthConfig = AuthConfig{User: tc.user, Password: tc.password}
+			if got := c.redactString(tc.msg); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestErrorsHideShortPassword(t *testing.T) {
+	c := NewClient(time.Second, nil, WithTransport(failingTransport{}))
+	c.RetryPolicy.MaxAttempts = 1
+	c.AuthConfig = AuthConfig{User: "someone@example.com", Password: "ab1"}
+
+	_, err := c.GetSeriesData("F073.TCO.PRE.Z.D", "", "")
+	if err == nil {
+		t.Fatal("expected error from failing transport")
+	}
+	if msg := err.Error(); strings.Contains(msg, "pass=ab1") || !strings.Contains(msg, "pass="+redacted) {
+		t.Errorf("expected the short password to be redacted from the query, got %v", msg)
+	}
+}
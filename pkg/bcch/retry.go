@@ -0,0 +1,124 @@
+package bcch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how transient failures of the BCCh API are retried.
+// Only timeouts, reset or refused connections, truncated responses, 429 and
+// 5xx responses are retried; every request to the API is a GET, so
+// retrying is always safe.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of the backoff, between 0 and 1, that is
+	// randomised to avoid synchronised retries.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// backoff returns the wait before the given retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= max(p.Multiplier, 1)
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if j := min(max(p.Jitter, 0), 1); j > 0 {
+		d = d*(1-j) + d*j*rand.Float64() // #nosec G404 -- jitter does not need a secure source
+	}
+	return time.Duration(d)
+}
+
+// StatusError is returned when the BCCh API answers with an HTTP error status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code over 399: %v", e.StatusCode)
+}
+
+// Is matches 429 responses with ErrRateLimited and 5xx ones with ErrUpstream.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUpstream:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// isTransient reports whether a failed attempt may succeed when retried.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		// cancelled or timed out by the caller
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	// every error of http.Client.Do is a net.Error, including bad URLs, TLS
+	// and DNS failures, so only timeouts and known transient causes count
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withRetry calls attempt until it succeeds, fails with a non-transient
+// error or the policy runs out of attempts. Errors report how many attempts
+// were made.
+func (c *Client) withRetry(ctx context.Context, attempt func() ([]byte, error)) ([]byte, error) {
+	maxAttempts := max(c.RetryPolicy.MaxAttempts, 1)
+
+	for n := 1; ; n++ {
+		body, err := attempt()
+		if err == nil {
+			return body, nil
+		}
+		if n >= maxAttempts || !isTransient(ctx, err) {
+			return nil, fmt.Errorf("request failed after %d attempt(s): %w", n, err)
+		}
+
+		timer := time.NewTimer(c.RetryPolicy.backoff(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request failed after %d attempt(s): %w", n, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
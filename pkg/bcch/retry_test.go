@@ -0,0 +1,63 @@
+package bcch
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestIsTransient(t *testing.T) {
+	// http.Client reports unsupported schemes without reaching the network
+	_, badScheme := http.Get("ftp://si3.bcentral.cl/SieteRestWS/")
+	if badScheme == nil {
+		t.Fatal("expected an error for an unsupported scheme")
+	}
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://si3.bcentral.cl/SieteRestWS/", Err: err}
+	}
+	syscallError := func(op string, errno syscall.Errno) error {
+		return urlError(&net.OpError{Op: op, Net: "tcp", Err: &os.SyscallError{Syscall: op, Err: errno}})
+	}
+
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"tls unknown authority": {err: urlError(x509.UnknownAuthorityError{}), want: false},
+		"unsupported scheme":    {err: badScheme, want: false},
+		"dns not found":         {err: urlError(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}), want: false},
+		"timeout":               {err: urlError(&net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}), want: true},
+		"deadline exceeded":     {err: fmt.Errorf("request: %w", context.DeadlineExceeded), want: true},
+		"connection reset":      {err: syscallError("read", syscall.ECONNRESET), want: true},
+		"connection refused":    {err: syscallError("connect", syscall.ECONNREFUSED), want: true},
+		"truncated response":    {err: urlError(io.ErrUnexpectedEOF), want: true},
+		"429":                   {err: &StatusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		"500":                   {err: &StatusError{StatusCode: http.StatusInternalServerError}, want: true},
+		"503":                   {err: &StatusError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		"400":                   {err: &StatusError{StatusCode: http.StatusBadRequest}, want: false},
+		"404":                   {err: &StatusError{StatusCode: http.StatusNotFound}, want: false},
+		"unknown series":        {err: fmt.Errorf("api: %w", ErrUnknownSeries), want: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isTransient(context.Background(), tc.err); got != tc.want {
+				t.Errorf("isTransient(%v): expected %v, got %v", tc.err, tc.want, got)
+			}
+		})
+	}
+
+	t.Run("canceled by the caller", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if isTransient(ctx, &StatusError{StatusCode: http.StatusServiceUnavailable}) {
+			t.Error("expected errors after cancellation not to be retried")
+		}
+	})
+}
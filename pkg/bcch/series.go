@@ -0,0 +1,163 @@
+package bcch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObservationDateLayout is the layout of IndexDateString in API responses.
+const ObservationDateLayout = "02-01-2006"
+
+// Frequency is the frequency at which a series is published.
+type Frequency string
+
+const (
+	FrequencyUnknown   Frequency = ""
+	FrequencyDaily     Frequency = "DAILY"
+	FrequencyMonthly   Frequency = "MONTHLY"
+	FrequencyQuarterly Frequency = "QUARTERLY"
+	FrequencyAnnual    Frequency = "ANNUAL"
+)
+
+// ParseFrequency parses a frequency as used by SearchSeries, either the
+// full name (e.g. "MONTHLY") or its single letter code (e.g. "M").
+func ParseFrequency(s string) (Frequency, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DAILY", "D":
+		return FrequencyDaily, nil
+	case "MONTHLY", "M":
+		return FrequencyMonthly, nil
+	case "QUARTERLY", "T", "Q":
+		return FrequencyQuarterly, nil
+	case "ANNUAL", "A":
+		return FrequencyAnnual, nil
+	}
+	return FrequencyUnknown, fmt.Errorf("unknown frequency %q", s)
+}
+
+// FrequencyFromSeriesID infers the frequency from the last segment of a
+// series ID, e.g. "F073.TCO.PRE.Z.D" is daily.
+func FrequencyFromSeriesID(seriesID string) Frequency {
+	i := strings.LastIndex(seriesID, ".")
+	if i < 0 {
+		return FrequencyUnknown
+	}
+	f, err := ParseFrequency(seriesID[i+1:])
+	if err != nil {
+		return FrequencyUnknown
+	}
+	return f
+}
+
+// ObservationStatus is the status code BCCh attaches to every observation.
+type ObservationStatus int
+
+const (
+	StatusUnknown ObservationStatus = iota
+	// StatusOK marks an observation with a published value.
+	StatusOK
+	// StatusNoData marks an observation without value ("ND").
+	StatusNoData
+)
+
+// ParseObservationStatus parses the statusCode of an observation.
+func ParseObservationStatus(s string) ObservationStatus {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "OK":
+		return StatusOK
+	case "ND":
+		return StatusNoData
+	}
+	return StatusUnknown
+}
+
+func (s ObservationStatus) String() string {
+	switch s {
+	case StatusOK:
+		return "OK"
+	case StatusNoData:
+		return "ND"
+	}
+	return "UNKNOWN"
+}
+
+func (s ObservationStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Observation is a single parsed data point of a series.
+type Observation struct {
+	Date time.Time
+	// Value is only meaningful when Missing is false.
+	Value float64
+	// Missing is set when BCCh has no value for the date, which the API
+	// reports as "NaN", an empty value or a non OK status.
+	Missing bool
+	Status  ObservationStatus
+}
+
+// MarshalJSON encodes the date as YYYY-MM-DD and missing values as null.
+func (o Observation) MarshalJSON() ([]byte, error) {
+	var value *float64
+	if !o.Missing {
+		value = &o.Value
+	}
+	return json.Marshal(struct {
+		Date   string            `json:"date"`
+		Value  *float64          `json:"value"`
+		Status ObservationStatus `json:"status"`
+	}{
+		Date:   o.Date.Format(time.DateOnly),
+		Value:  value,
+		Status: o.Status,
+	})
+}
+
+// Series is a parsed series with typed observations.
+type Series struct {
+	ID           string        `json:"seriesId"`
+	SpanishTitle string        `json:"spanishTitle"`
+	EnglishTitle string        `json:"englishTitle"`
+	Frequency    Frequency     `json:"frequency"`
+	Observations []Observation `json:"observations"`
+}
+
+// ToSeries converts the raw response into a Series with parsed dates and
+// values.
+func (r SeriesDataResp) ToSeries() (Series, error) {
+	s := Series{
+		ID:           r.Series.SeriesID,
+		SpanishTitle: r.Series.DescripEsp,
+		EnglishTitle: r.Series.DescripIng,
+		Frequency:    FrequencyFromSeriesID(r.Series.SeriesID),
+		Observations: make([]Observation, 0, len(r.Series.Obs)),
+	}
+
+	for _, obs := range r.Series.Obs {
+		date, err := time.Parse(ObservationDateLayout, obs.IndexDateString)
+		if err != nil {
+			return s, fmt.Errorf("series %s: invalid observation date %q: %w", s.ID, obs.IndexDateString, err)
+		}
+
+		o := Observation{
+			Date:   date,
+			Status: ParseObservationStatus(obs.StatusCode),
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(obs.Value), 64)
+		if err != nil && strings.TrimSpace(obs.Value) != "" {
+			return s, fmt.Errorf("series %s: invalid value %q on %s: %w", s.ID, obs.Value, obs.IndexDateString, err)
+		}
+		if err != nil || math.IsNaN(value) || o.Status == StatusNoData {
+			o.Missing = true
+		} else {
+			o.Value = value
+		}
+		s.Observations = append(s.Observations, o)
+	}
+
+	return s, nil
+}
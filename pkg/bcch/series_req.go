@@ -0,0 +1,196 @@
+package bcch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GetAvailableSeries returns the catalog of series with the given frequency
+// (DAILY, MONTHLY, QUARTERLY or ANNUAL).
+func (c *Client) GetAvailableSeries(seriesFrequency string) (AvailableSeriesResp, error) {
+	return c.GetAvailableSeriesContext(context.Background(), seriesFrequency)
+}
+
+// GetAvailableSeriesContext is like GetAvailableSeries but aborts the request
+// when ctx is done.
+func (c *Client) GetAvailableSeriesContext(ctx context.Context, seriesFrequency string) (AvailableSeriesResp, error) {
+
+	sf := strings.ToUpper(seriesFrequency)
+
+	params := url.Values{}
+	params.Set("function", "SearchSeries")
+	params.Set("frequency", sf)
+
+	body, err := c.fetch(ctx, params)
+	if err != nil {
+		return AvailableSeriesResp{}, err
+	}
+
+	AvailableSeries := AvailableSeriesResp{}
+	err = json.Unmarshal(body, &AvailableSeries)
+	if err != nil {
+		return AvailableSeries, fmt.Errorf("error during unmarshal of body (JSON): %v", err)
+	}
+	if err := newAPIError(AvailableSeries.Codigo, AvailableSeries.Descripcion); err != nil {
+		return AvailableSeries, c.redact(err)
+	}
+
+	return AvailableSeries, nil
+}
+
+// GetSeriesData returns the observations of a series between firstDate and
+// lastDate, formatted as YYYY-MM-DD. Empty dates leave the range open.
+func (c *Client) GetSeriesData(seriesID, firstDate, lastDate string) (SeriesDataResp, error) {
+	return c.GetSeriesDataContext(context.Background(), seriesID, firstDate, lastDate)
+}
+
+// GetSeriesDataContext is like GetSeriesData but aborts the request when ctx
+// is done.
+func (c *Client) GetSeriesDataContext(ctx context.Context, seriesID, firstDate, lastDate string) (SeriesDataResp, error) {
+	params := url.Values{}
+	params.Set("function", "GetSeries")
+	params.Set("timeseries", seriesID)
+	if firstDate != "" {
+		params.Set("firstdate", firstDate)
+	}
+	if lastDate != "" {
+		params.Set("lastdate", lastDate)
+	}
+
+	body, err := c.fetch(ctx, params)
+	if err != nil {
+		return SeriesDataResp{}, err
+	}
+
+	SeriesDataResp := SeriesDataResp{}
+	err = json.Unmarshal(body, &SeriesDataResp)
+	if err != nil {
+		return SeriesDataResp, fmt.Errorf("error during unmarshal of body (JSON): %v", err)
+	}
+	if err := newAPIError(SeriesDataResp.Codigo, SeriesDataResp.Descripcion); err != nil {
+		return SeriesDataResp, c.redact(fmt.Errorf("series %s: %w", seriesID, err))
+	}
+
+	return SeriesDataResp, nil
+}
+
+// fetch returns the response body for the given request parameters, serving
+// it from the cache when possible and caching successful responses. In
+// offline mode only the cache is used, including expired entries.
+// Concurrent fetches of the same request share a single request. Returned
+// errors never contain the credentials.
+func (c *Client) fetch(ctx context.Context, params url.Values) ([]byte, error) {
+	body, err := c.fetchQuery(ctx, params.Encode())
+	return body, c.redact(err)
+}
+
+// fetchQuery is fetch for a canonical, credential-free query.
+func (c *Client) fetchQuery(ctx context.Context, query string) ([]byte, error) {
+	key := c.cacheKey(query)
+	if c.Offline {
+		cachedValues, createdAt, ok := c.cache.GetStale(key)
+		if !ok {
+			return nil, &NotCachedError{Query: query}
+		}
+		if age := time.Since(createdAt); age > c.cache.Interval() {
+			c.warnf("offline mode: serving cached response from %v ago", age.Truncate(time.Minute))
+		}
+		return cachedValues, nil
+	}
+
+	if cachedValues, ok := c.cache.Get(key); ok {
+		//cache hit
+		return cachedValues, nil
+	}
+
+	return c.flights.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		body, err := c.withRetry(ctx, func() ([]byte, error) {
+			return c.do(ctx, query)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		c.addToCache(key, body)
+
+		return body, nil
+	})
+}
+
+// do performs a single request to the API.
+func (c *Client) do(ctx context.Context, query string) ([]byte, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	fullURL, err := c.requestURL(query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making get request: %w", err)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error during 'Do' of request: %w", redactURLError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 399 {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error during reading of response body: %w", err)
+	}
+	return body, nil
+}
+
+// requestURL builds the full request URL for the given query, adding the
+// credentials.
+func (c *Client) requestURL(query string) (string, error) {
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("error parsing query: %w", err)
+	}
+	params.Set("user", c.AuthConfig.User)
+	params.Set("pass", c.AuthConfig.Password)
+	return c.endpoint() + "?" + params.Encode(), nil
+}
+
+// cacheKey returns the key of the response to query: the request URL
+// without the credentials, so that credentials never end up in the cache
+// and responses of different servers, e.g. a mirror set with WithBaseURL,
+// are kept apart.
+func (c *Client) cacheKey(query string) string {
+	return c.endpoint() + "?" + query
+}
+
+func (c *Client) endpoint() string {
+	return c.baseURL + "SieteRestWS.ashx"
+}
+
+// addToCache stores a successful response body. Responses carrying an API
+// error code are not cached, so a later request can succeed.
+func (c *Client) addToCache(key string, body []byte) {
+	var status struct {
+		Codigo int `json:"Codigo"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil || status.Codigo != 0 {
+		return
+	}
+	if err := c.cache.Add(key, body); err != nil {
+		c.warnf("could not cache response: %v", c.redact(err))
+	}
+}
@@ -0,0 +1,413 @@
+package bcch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bcchcache "github.com/iferdel/chile-economic-indexes-cli/v3/internal/bcch-cache"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+const (
+	dailySeries   = "F073.TCO.PRE.Z.D"
+	monthlySeries = "F049.DES.TAS.INE.10.M"
+)
+
+func newTestClient(t *testing.T, srv *bcchtest.Server) *bcch.Client {
+	t.Helper()
+	cache := bcchcache.NewCache(time.Minute)
+	c := bcch.NewClient(
+		time.Second,
+		&cache,
+		bcch.WithBaseURL(srv.URL),
+	)
+	c.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+	c.RetryPolicy.InitialBackoff = time.Millisecond
+	return c
+}
+
+func TestGetSeriesData(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	data, err := c.GetSeriesData(dailySeries, "2024-01-03", "2024-01-08")
+	if err != nil {
+		t.Fatalf("unexpected error fetching %v: %v", dailySeries, err)
+	}
+	if data.Series.SeriesID != dailySeries {
+		t.Errorf("expected series %v, got %v", dailySeries, data.Series.SeriesID)
+	}
+	if got := len(data.Series.Obs); got != 4 {
+		t.Errorf("expected 4 observations between the dates, got %v", got)
+	}
+
+	// second call is served from the cache
+	if _, err := c.GetSeriesData(dailySeries, "2024-01-03", "2024-01-08"); err != nil {
+		t.Fatalf("unexpected error fetching %v from cache: %v", dailySeries, err)
+	}
+	if got := srv.Requests(dailySeries); got != 1 {
+		t.Errorf("expected 1 request to the server, got %v", got)
+	}
+}
+
+func TestGetSeriesDataAPIErrors(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	cases := map[string]struct {
+		seriesID  string
+		firstDate string
+		lastDate  string
+		password  string
+		want      error
+	}{
+		"unknown series":      {seriesID: "NOT.A.SERIES", want: bcch.ErrUnknownSeries},
+		"invalid credentials": {seriesID: dailySeries, password: "wrong", want: bcch.ErrInvalidCredentials},
+		"invalid date range":  {seriesID: dailySeries, firstDate: "2024-02-01", lastDate: "2024-01-01", want: bcch.ErrInvalidDateRange},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, srv)
+			if tc.password != "" {
+				c.AuthConfig.Password = tc.password
+			}
+			_, err := c.GetSeriesData(tc.seriesID, tc.firstDate, tc.lastDate)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestGetSeriesDataRetries(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	t.Run("transient failures", func(t *testing.T) {
+		c := newTestClient(t, srv)
+		srv.FailNext(2, http.StatusServiceUnavailable)
+		if _, err := c.GetSeriesData(dailySeries, "", ""); err != nil {
+			t.Fatalf("expected success after retries, got %v", err)
+		}
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		c := newTestClient(t, srv)
+		srv.FailNext(3, http.StatusBadGateway)
+		_, err := c.GetSeriesData(monthlySeries, "", "")
+		if !errors.Is(err, bcch.ErrUpstream) {
+			t.Errorf("expected upstream error, got %v", err)
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		c := newTestClient(t, srv)
+		before := srv.TotalRequests()
+		srv.FailNext(1, http.StatusBadRequest)
+		if _, err := c.GetSeriesData(dailySeries, "2024-01-02", ""); err == nil {
+			t.Fatal("expected error on bad request")
+		}
+		if got := srv.TotalRequests() - before; got != 1 {
+			t.Errorf("expected 1 request, got %v", got)
+		}
+	})
+}
+
+func TestGetSeriesDataOffline(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	if _, err := c.GetSeriesData(dailySeries, "", ""); err != nil {
+		t.Fatalf("unexpected error fetching %v: %v", dailySeries, err)
+	}
+
+	c.Offline = true
+	if _, err := c.GetSeriesData(dailySeries, "", ""); err != nil {
+		t.Errorf("expected cached series in offline mode, got %v", err)
+	}
+	if _, err := c.GetSeriesData(monthlySeries, "", ""); !errors.Is(err, bcch.ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got %v", err)
+	}
+	if got := srv.TotalRequests(); got != 1 {
+		t.Errorf("expected offline mode not to reach the server, got %v requests", got)
+	}
+}
+
+// staleCache holds a single response stored an hour ago, which has expired.
+type staleCache struct {
+	key   string
+	value []byte
+}
+
+func (sc staleCache) Get(string) ([]byte, bool) { return nil, false }
+func (sc staleCache) GetStale(key string) ([]byte, time.Time, bool) {
+	if key != sc.key {
+		return nil, time.Time{}, false
+	}
+	return sc.value, time.Now().Add(-time.Hour), true
+}
+func (sc staleCache) Add(string, []byte) error { return nil }
+func (sc staleCache) Interval() time.Duration  { return time.Minute }
+
+func TestGetSeriesDataOfflineStale(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+
+	body := `{"Codigo":0,"Descripcion":"Success","Series":{"seriesId":"F073.TCO.PRE.Z.D","Obs":[{"indexDateString":"02-01-2024","value":"877.12","statusCode":"OK"}]}}`
+	cache := staleCache{key: srv.URL + "/SieteRestWS.ashx?function=GetSeries&timeseries=" + dailySeries, value: []byte(body)}
+	var warnings bytes.Buffer
+	c := bcch.NewClient(time.Second, cache, bcch.WithBaseURL(srv.URL), bcch.WithLogger(log.New(&warnings, "", 0)))
+	c.Offline = true
+
+	data, err := c.GetSeriesData(dailySeries, "", "")
+	if err != nil {
+		t.Fatalf("expected expired response in offline mode, got %v", err)
+	}
+	if len(data.Series.Obs) != 1 || data.Series.Obs[0].Value != "877.12" {
+		t.Errorf("expected the cached observation, got %+v", data.Series.Obs)
+	}
+	if !strings.Contains(warnings.String(), "serving cached response from 1h0m0s ago") {
+		t.Errorf("expected a warning about the expired response, got %q", warnings.String())
+	}
+
+	var notCached *bcch.NotCachedError
+	if _, err := c.GetSeriesData(dailySeries, "2024-01-01", ""); !errors.As(err, &notCached) || !errors.Is(err, bcch.ErrNotCached) {
+		t.Errorf("expected NotCachedError for another range, got %v", err)
+	}
+	if _, err := c.GetAvailableSeries("DAILY"); !errors.Is(err, bcch.ErrNotCached) {
+		t.Errorf("expected ErrNotCached searching offline, got %v", err)
+	}
+	if got := srv.TotalRequests(); got != 0 {
+		t.Errorf("expected offline mode not to reach the server, got %v requests", got)
+	}
+}
+
+func TestGetSeriesDataCoalesces(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	srv.SetLatency(50 * time.Millisecond)
+	c := newTestClient(t, srv)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetSeriesData(dailySeries, "", ""); err != nil {
+				t.Errorf("unexpected error fetching %v: %v", dailySeries, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := srv.Requests(dailySeries); got != 1 {
+		t.Errorf("expected concurrent fetches to share 1 request, got %v", got)
+	}
+}
+
+func TestGetSeriesDataContext(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	srv.SetLatency(time.Second)
+	c := newTestClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetSeriesDataContext(ctx, dailySeries, "", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected request to be aborted, took %v", elapsed)
+	}
+}
+
+func TestGetSeriesDataContextCanceled(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	srv.SetLatency(time.Second)
+	c := newTestClient(t, srv)
+
+	fetches := map[string]func(ctx context.Context) error{
+		"GetSeriesDataContext": func(ctx context.Context) error {
+			_, err := c.GetSeriesDataContext(ctx, dailySeries, "", "")
+			return err
+		},
+		"GetAvailableSeriesContext": func(ctx context.Context) error {
+			_, err := c.GetAvailableSeriesContext(ctx, "DAILY")
+			return err
+		},
+	}
+	for name, fetch := range fetches {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			// cancel once the request is in flight
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := fetch(ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected canceled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected request to be aborted, took %v", elapsed)
+			}
+		})
+	}
+	if got := srv.TotalRequests(); got != 2 {
+		t.Errorf("expected each canceled request to reach the server once, got %v", got)
+	}
+}
+
+func TestGetAvailableSeries(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	resp, err := c.GetAvailableSeries("monthly")
+	if err != nil {
+		t.Fatalf("unexpected error searching series: %v", err)
+	}
+	if got := len(resp.SeriesInfos); got != 2 {
+		t.Errorf("expected 2 monthly series, got %v", got)
+	}
+}
+
+func TestGetMultipleSeriesData(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	ids := []string{dailySeries, monthlySeries, "NOT.A.SERIES", dailySeries}
+	results, err := c.GetMultipleSeriesData(ids, "", "", &bcch.FetchOptions{MaxConcurrency: 2})
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error, got %v", err)
+	}
+
+	wantStatus := []bcch.FetchStatus{bcch.FetchOK, bcch.FetchOK, bcch.FetchFailed, bcch.FetchOK}
+	if len(results) != len(ids) {
+		t.Fatalf("expected %v results, got %v", len(ids), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i || result.SeriesID != ids[i] {
+			t.Errorf("expected result %v for %v, got %v for %v", i, ids[i], result.Index, result.SeriesID)
+		}
+		if result.Status != wantStatus[i] {
+			t.Errorf("%v: expected status %v, got %v (%v)", result.SeriesID, wantStatus[i], result.Status, result.Err)
+		}
+		if result.Status == bcch.FetchOK && result.Data.Series.SeriesID != ids[i] {
+			t.Errorf("expected data of %v, got %v", ids[i], result.Data.Series.SeriesID)
+		}
+		if result.Duration <= 0 {
+			t.Errorf("%v: expected a positive duration", result.SeriesID)
+		}
+	}
+	if !errors.Is(results[2].Err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error, got %v", results[2].Err)
+	}
+}
+
+// blockingTransport answers GetSeries for the failing series with an
+// unknown series error and blocks every other request until it is
+// canceled.
+type blockingTransport struct {
+	failing string
+}
+
+func (bt blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("timeseries") == bt.failing {
+		body := `{"Codigo":-50,"Descripcion":"The series does not exist"}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestGetMultipleSeriesDataFailFast(t *testing.T) {
+	c := bcch.NewClient(time.Minute, nil, bcch.WithTransport(blockingTransport{failing: "NOT.A.SERIES"}))
+	c.AuthConfig = bcch.AuthConfig{User: bcchtest.User, Password: bcchtest.Password}
+
+	ids := []string{dailySeries, "NOT.A.SERIES", monthlySeries}
+	results, err := c.GetMultipleSeriesData(ids, "", "", &bcch.FetchOptions{MaxConcurrency: len(ids), Mode: bcch.FailFast})
+	if !errors.Is(err, bcch.ErrUnknownSeries) {
+		t.Errorf("expected unknown series error, got %v", err)
+	}
+	for _, result := range results {
+		if result.SeriesID == "NOT.A.SERIES" {
+			if result.Status != bcch.FetchFailed {
+				t.Errorf("expected failing series to fail, got %v", result.Status)
+			}
+			continue
+		}
+		if result.Status != bcch.FetchCanceled || !errors.Is(result.Err, bcch.ErrFetchAborted) {
+			t.Errorf("%v: expected aborted, got %v (%v)", result.SeriesID, result.Status, result.Err)
+		}
+	}
+}
+
+func TestGetMultipleSeriesDataOnResult(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	ids := []string{dailySeries, monthlySeries, "F074.IPC.VAR.Z.Z.C.M", "NOT.A.SERIES"}
+	// calls must not overlap, so no locking is needed here; -race checks it
+	var streamed []string
+	results, _ := c.GetMultipleSeriesData(ids, "", "", &bcch.FetchOptions{
+		MaxConcurrency: len(ids),
+		OnResult: func(result bcch.SeriesResult) {
+			streamed = append(streamed, result.SeriesID)
+		},
+	})
+	if len(streamed) != len(ids) {
+		t.Fatalf("expected %v streamed results, got %v", len(ids), streamed)
+	}
+	for _, id := range ids {
+		if !slices.Contains(streamed, id) {
+			t.Errorf("expected %v to be streamed, got %v", id, streamed)
+		}
+	}
+	if len(results) != len(ids) {
+		t.Errorf("expected %v results, got %v", len(ids), len(results))
+	}
+}
+
+func TestGetMultipleSeriesDataCanceled(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := c.GetMultipleSeriesDataContext(ctx, []string{dailySeries, monthlySeries}, "", "", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the fetch to report the cancellation, got %v", err)
+	}
+	for _, result := range results {
+		if result.Status != bcch.FetchCanceled || !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("%v: expected canceled, got %v (%v)", result.SeriesID, result.Status, result.Err)
+		}
+	}
+	if n := srv.TotalRequests(); n != 0 {
+		t.Errorf("expected no requests, got %v", n)
+	}
+}
@@ -0,0 +1,117 @@
+package bcch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestToSeries(t *testing.T) {
+	const body = `{
+		"Codigo": 0,
+		"Descripcion": "Success",
+		"Series": {
+			"descripEsp": "Tipo de cambio",
+			"descripIng": "Exchange rate",
+			"seriesId": "F073.TCO.PRE.Z.D",
+			"Obs": [
+				{"indexDateString": "02-01-2024", "value": "877.12", "statusCode": "OK"},
+				{"indexDateString": "03-01-2024", "value": "NaN", "statusCode": "ND"},
+				{"indexDateString": "04-01-2024", "value": "", "statusCode": "OK"}
+			]
+		},
+		"SeriesInfos": []
+	}`
+
+	var resp SeriesDataResp
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unexpected error during unmarshal: %v", err)
+	}
+	s, err := resp.ToSeries()
+	if err != nil {
+		t.Fatalf("unexpected error converting series: %v", err)
+	}
+
+	if s.Frequency != FrequencyDaily {
+		t.Errorf("expected frequency %v, got %v", FrequencyDaily, s.Frequency)
+	}
+	if len(s.Observations) != 3 {
+		t.Fatalf("expected 3 observations, got %v", len(s.Observations))
+	}
+
+	first := s.Observations[0]
+	if !first.Date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected date 2024-01-02, got %v", first.Date)
+	}
+	if first.Missing || first.Value != 877.12 || first.Status != StatusOK {
+		t.Errorf("unexpected first observation: %+v", first)
+	}
+	for _, obs := range s.Observations[1:] {
+		if !obs.Missing {
+			t.Errorf("expected observation on %v to be missing", obs.Date)
+		}
+	}
+
+	got, err := json.Marshal(s.Observations[1])
+	if err != nil {
+		t.Fatalf("unexpected error marshalling observation: %v", err)
+	}
+	if want := `{"date":"2024-01-03","value":null,"status":"ND"}`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestToSeriesInvalidValue(t *testing.T) {
+	const body = `{"Series": {"seriesId": "UF", "Obs": [{"indexDateString": "01-01-2024", "value": "abc", "statusCode": "OK"}]}}`
+
+	var resp SeriesDataResp
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unexpected error during unmarshal: %v", err)
+	}
+	if _, err := resp.ToSeries(); err == nil {
+		t.Errorf("expected error converting invalid value")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC) }
+	daily := Series{ID: "D", Observations: []Observation{
+		{Date: day(1), Value: 1},
+		{Date: day(2), Value: 2},
+		{Date: day(3), Missing: true},
+	}}
+	monthly := Series{ID: "M", Observations: []Observation{
+		{Date: day(1), Value: 10},
+	}}
+	later := Series{ID: "L", Observations: []Observation{
+		{Date: day(4), Value: 40},
+	}}
+
+	rows := Align(daily, monthly, later)
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %v", len(rows))
+	}
+	// present[i][j] tells whether row i has an observation of series j
+	present := [][]bool{
+		{true, true, false},
+		{true, false, false},
+		{true, false, false},
+		{false, false, true},
+	}
+	for i, row := range rows {
+		if !row.Date.Equal(day(i + 1)) {
+			t.Errorf("row %v: expected date %v, got %v", i, day(i+1), row.Date)
+		}
+		for j, obs := range row.Observations {
+			if (obs != nil) != present[i][j] {
+				t.Errorf("row %v, series %v: expected present %v, got %v", i, j, present[i][j], obs)
+			}
+		}
+	}
+	if v := rows[0].Observations[1].Value; v != 10 {
+		t.Errorf("expected monthly value 10 on the first row, got %v", v)
+	}
+	if !rows[2].Observations[0].Missing {
+		t.Errorf("expected missing value to be kept")
+	}
+}
@@ -0,0 +1,20 @@
+package bcch
+
+import "context"
+
+// SeriesService is the set of series queries answered by Client. Code that
+// only reads series can depend on it instead of *Client, so that it can be
+// driven by mocks, decorators such as caches or other data sources.
+type SeriesService interface {
+	// GetAvailableSeriesContext returns the catalog of series with the given
+	// frequency.
+	GetAvailableSeriesContext(ctx context.Context, seriesFrequency string) (AvailableSeriesResp, error)
+	// GetSeriesDataContext returns the observations of a series between
+	// firstDate and lastDate, formatted as YYYY-MM-DD.
+	GetSeriesDataContext(ctx context.Context, seriesID, firstDate, lastDate string) (SeriesDataResp, error)
+	// GetMultipleSeriesDataContext fetches several series, returning one
+	// result per requested ID in the same order.
+	GetMultipleSeriesDataContext(ctx context.Context, seriesIDs []string, firstDate, lastDate string, opts *FetchOptions) ([]SeriesResult, error)
+}
+
+var _ SeriesService = (*Client)(nil)
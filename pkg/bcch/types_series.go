@@ -1,5 +1,6 @@
-package bcchapi
+package bcch
 
+// AvailableSeriesResp is the raw SearchSeries response.
 type AvailableSeriesResp struct {
 	Codigo      int    `json:"Codigo"`
 	Descripcion string `json:"Descripcion"`
@@ -9,18 +10,24 @@ type AvailableSeriesResp struct {
 		SeriesID   any `json:"seriesId"`
 		Obs        any `json:"Obs"`
 	} `json:"Series"`
-	SeriesInfos []struct {
-		SeriesID         string `json:"seriesId"`
-		FrequencyCode    string `json:"frequencyCode"`
-		SpanishTitle     string `json:"spanishTitle"`
-		EnglishTitle     string `json:"englishTitle"`
-		FirstObservation string `json:"firstObservation"`
-		LastObservation  string `json:"lastObservation"`
-		UpdatedAt        string `json:"updatedAt"`
-		CreatedAt        string `json:"createdAt"`
-	} `json:"SeriesInfos"`
+	SeriesInfos []SeriesInfo `json:"SeriesInfos"`
 }
 
+// SeriesInfo is a catalog entry of SearchSeries. Dates are formatted as
+// ObservationDateLayout.
+type SeriesInfo struct {
+	SeriesID         string `json:"seriesId"`
+	FrequencyCode    string `json:"frequencyCode"`
+	SpanishTitle     string `json:"spanishTitle"`
+	EnglishTitle     string `json:"englishTitle"`
+	FirstObservation string `json:"firstObservation"`
+	LastObservation  string `json:"lastObservation"`
+	UpdatedAt        string `json:"updatedAt"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+// SeriesDataResp is the raw GetSeries response. ToSeries converts it to a
+// typed Series.
 type SeriesDataResp struct {
 	Codigo      int    `json:"Codigo"`
 	Descripcion string `json:"Descripcion"`
@@ -0,0 +1,53 @@
+package bcch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// verifySeriesID is a daily series available to every account, the
+	// observed exchange rate, requested for a single day to keep the
+	// verification call small.
+	verifySeriesID = "F073.TCO.PRE.Z.D"
+	verifyDate     = "2024-01-02"
+)
+
+// VerifyCredentials performs a minimal authenticated request, bypassing the
+// cache, and returns nil if BCCh accepts the credentials. Rejections are
+// reported with an *APIError matching ErrInvalidCredentials or
+// ErrAccountLocked.
+func (c *Client) VerifyCredentials(ctx context.Context) error {
+	if c.Offline {
+		return errors.New("cannot verify credentials in offline mode")
+	}
+	if c.AuthConfig.User == "" || c.AuthConfig.Password == "" {
+		return fmt.Errorf("missing user or password: %w", ErrInvalidCredentials)
+	}
+
+	params := url.Values{}
+	params.Set("function", "GetSeries")
+	params.Set("timeseries", verifySeriesID)
+	params.Set("firstdate", verifyDate)
+	params.Set("lastdate", verifyDate)
+	query := params.Encode()
+
+	body, err := c.withRetry(ctx, func() ([]byte, error) {
+		return c.do(ctx, query)
+	})
+	if err != nil {
+		return c.redact(err)
+	}
+
+	var status struct {
+		Codigo      int    `json:"Codigo"`
+		Descripcion string `json:"Descripcion"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("error during unmarshal of body (JSON): %v", err)
+	}
+	return c.redact(newAPIError(status.Codigo, status.Descripcion))
+}
@@ -0,0 +1,47 @@
+package bcch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch"
+	"github.com/iferdel/chile-economic-indexes-cli/v3/pkg/bcch/bcchtest"
+)
+
+func TestVerifyCredentials(t *testing.T) {
+	srv := bcchtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	for range 2 {
+		if err := c.VerifyCredentials(context.Background()); err != nil {
+			t.Fatalf("unexpected error verifying valid credentials: %v", err)
+		}
+	}
+	// verification is never answered from the cache
+	if got := srv.TotalRequests(); got != 2 {
+		t.Errorf("expected 2 requests to the server, got %v", got)
+	}
+
+	srv.FailNextWithCode(1, bcchtest.CodeInvalidCredentials, "Usuario bloqueado")
+	err := c.VerifyCredentials(context.Background())
+	if !errors.Is(err, bcch.ErrAccountLocked) {
+		t.Errorf("expected ErrAccountLocked, got %v", err)
+	}
+
+	srv.SetCredentials("other-user", "other-password")
+	err = c.VerifyCredentials(context.Background())
+	if !errors.Is(err, bcch.ErrInvalidCredentials) || errors.Is(err, bcch.ErrAccountLocked) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	requests := srv.TotalRequests()
+	c.AuthConfig.Password = ""
+	if err := c.VerifyCredentials(context.Background()); !errors.Is(err, bcch.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials without a password, got %v", err)
+	}
+	if got := srv.TotalRequests(); got != requests {
+		t.Errorf("expected no request without a password, got %v", got-requests)
+	}
+}